@@ -4,6 +4,8 @@ package argp
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -20,13 +22,62 @@ const (
 	// An alias option will be resolved to a non-alias option.
 	OPTION_ALIAS = 0x4
 
+	// Mark this option as mandatory. Parsing fails with ErrRequired if the
+	// option is not supplied by the arguments or the environment variable.
+	// To accept the option from a configuration file, parse with
+	// PARSE_DEFER_REQUIRED and call [ParseResult.CheckRequired] after
+	// [ParseResult.Merge].
+	OPTION_REQUIRED = 0x8
+
 	// [Private] Mark this option as "Non option". This flag is used to
 	// return the non-option argument to support option reordering
 	_OPTION_NON_OPTION_ARG = 0x20
 
+	// Stop option processing at the first non-option argument. The
+	// non-option argument and all the following arguments are returned
+	// untouched in ParseResult.Args. Same as the "+" prefix of GNU getopt.
+	PARSE_REQUIRE_ORDER = 0x1
+
+	// Enable PARSE_REQUIRE_ORDER if the POSIXLY_CORRECT environment variable
+	// is set.
+	PARSE_POSIXLY_CORRECT = 0x2
+
+	// Accept an unambiguous prefix of the long option name, e.g. --verb for
+	// --verbose. An exact match always wins over the prefix match.
+	PARSE_LONG_PREFIX = 0x4
+
+	// Replace "@file" arguments with the contents of the file before
+	// parsing. See [ExpandResponseFiles].
+	PARSE_RESPONSE_FILE = 0x8
+
+	// Skip the check of the options with OPTION_REQUIRED. Call
+	// [ParseResult.CheckRequired] after merging the other sources.
+	PARSE_DEFER_REQUIRED = 0x10
+
+	// The source of the Result
+	SOURCE_ARGS   = 0 // Supplied in the string array
+	SOURCE_ENV    = 1 // Supplied by the environment variable
+	SOURCE_CONFIG = 2 // Supplied by the configuration file
+
 	ErrInvalid = "invalid option"
 	ErrMissing = "option requires an argument"
 	ErrTooMany = "option takes no arguments"
+	ErrCommand = "unknown command"
+	ErrValue   = "invalid argument"
+
+	ErrRequired = "missing required option"
+	ErrConflict = "conflicting options"
+	ErrTogether = "options must be used together"
+	ErrOneOf    = "one of the options is required"
+
+	ErrMissingArg  = "missing argument"
+	ErrTooManyArgs = "too many arguments"
+
+	ErrAmbiguous = "ambiguous option"
+	ErrSyntax    = "syntax error"
+	ErrQuote     = "unterminated quote"
+	ErrRecursive = "recursive response file"
+	ErrResponse  = "cannot read response file"
 )
 
 // Option struct represents a single option.
@@ -38,6 +89,10 @@ type Option struct {
 	ArgName string // The name of argument if it takes one.
 	Flags   int    // Option flags
 	Doc     string // Description, or a single line text for header/line
+	Value   Value  // Receives the converted argument. Set nil if unused.
+	Env     string // The environment variable used if the option is absent
+
+	Completer Completer // Completes the argument. Set nil if unused.
 }
 
 // Returns true if the short name or long name equals the argument
@@ -45,21 +100,123 @@ func (o *Option) Is(name string) bool {
 	return !empty_str(name) && (name == string(o.Short) || name == o.Long)
 }
 
+// Returns the long name if available, otherwise the short name
+func (o *Option) Name() string {
+	if !empty_str(o.Long) {
+		return o.Long
+	} else if !empty_rune(o.Short) {
+		return string(o.Short)
+	} else {
+		return ""
+	}
+}
+
 // Error object implements error interface, and extends the option entry
 // which raised an error.
 type Error struct {
 	Option
 	Message string
+	Arg     string // The offending argument or option argument, if any
+	Err     error  // The cause of the error, e.g. returned by Value.Set
+
+	ArgumentName string // The name of the non-option argument, e.g. "FILE"
+
+	candidates string // Ambiguous matches, or suggestions, separated by "\n"
+	missing    string // Labels of the options not supplied, separated by "\n"
+
+	File   string // The file name, if the error is found in a file
+	Line   int    // The line number in the file or text, starting from 1
+	Column int    // The column number in the line, if available
+}
+
+// Returns the long names of the ambiguous matches for ErrAmbiguous, or the
+// suggestions for ErrInvalid
+func (e Error) Candidates() []string {
+	return splitList(e.candidates)
+}
+
+// Returns all the options not supplied for ErrRequired, ErrTogether and
+// ErrOneOf, named as in the message, e.g. "--output (-o)"
+func (e Error) Missing() []string {
+	return splitList(e.missing)
+}
+
+// Returns the cause of the error
+func (e Error) Unwrap() error {
+	return e.Err
 }
 
 func (e Error) Error() string {
-	if !empty_str(e.Long) && !empty_rune(e.Short) {
-		return fmt.Sprintf("%s: --%s (-%c)", e.Message, e.Long, e.Short)
-	} else if !empty_str(e.Long) {
-		return fmt.Sprintf("%s: --%s", e.Message, e.Long)
+	text := e.text()
+	if !empty_str(e.candidates) {
+		candidates := "--" + strings.Join(e.Candidates(), ", --")
+		if e.Message == ErrInvalid {
+			text = fmt.Sprintf("%s (did you mean %s?)", text, candidates)
+		} else {
+			text = fmt.Sprintf("%s (candidates: %s)", text, candidates)
+		}
+	}
+	if e.Column > 0 {
+		text = fmt.Sprintf("%d:%d: %s", e.Line, e.Column, text)
+	} else if e.Line > 0 {
+		text = fmt.Sprintf("%d: %s", e.Line, text)
+	}
+	if !empty_str(e.File) {
+		text = fmt.Sprintf("%s:%s", e.File, text)
+	}
+	return text
+}
+
+func (e Error) text() string {
+	message := e.Message
+	if strings.Contains(e.missing, "\n") && empty_str(e.Arg) {
+		return fmt.Sprintf("%s: %s", message, strings.Join(e.Missing(), ", "))
+	}
+	if !empty_str(e.ArgumentName) {
+		if !empty_str(e.Arg) {
+			message = fmt.Sprintf("%s '%s'", message, e.Arg)
+		}
+		return fmt.Sprintf("%s: %s", message, e.ArgumentName)
+	}
+	if empty_str(e.Arg) && empty_rune(e.Short) && empty_str(e.Long) {
+		return message
+	}
+	if !empty_str(e.Arg) {
+		if empty_rune(e.Short) && empty_str(e.Long) {
+			return fmt.Sprintf("%s: %s", message, e.Arg)
+		}
+		message = fmt.Sprintf("%s '%s'", message, e.Arg)
+	}
+	return fmt.Sprintf("%s: %s", message, e.label())
+}
+
+// Returns the option names for the messages, e.g. "--long (-s)"
+func (o *Option) label() string {
+	if !empty_str(o.Long) && !empty_rune(o.Short) {
+		return fmt.Sprintf("--%s (-%c)", o.Long, o.Short)
+	} else if !empty_str(o.Long) {
+		return fmt.Sprintf("--%s", o.Long)
 	} else {
-		return fmt.Sprintf("%s: -%c", e.Message, e.Short)
+		return fmt.Sprintf("-%c", o.Short)
+	}
+}
+
+// Returns the labels of the options separated by "\n", which keeps [Error]
+// comparable
+func joinLabels(options []Option) string {
+	var labels []string
+	for i := range options {
+		labels = append(labels, options[i].label())
+	}
+	return strings.Join(labels, "\n")
+}
+
+// Returns the list separated by "\n", or nil if empty
+func splitList(list string) []string {
+	if empty_str(list) {
+		return nil
 	}
+	return strings.Split(list, "\n")
 }
 
 // Result is an individual successfully parsed option. It embeds the original
@@ -68,6 +225,16 @@ type Result struct {
 	Option
 	InputString string // The original string supplied in the argument
 	Optarg      string // option argument
+	Index       int    // The index of the argument in the string array
+	Source      int    // Where the option was supplied (SOURCE_*)
+
+	short bool // Supplied as a short option
+}
+
+// Returns true if the result is a non-option argument. The argument is
+// stored in Optarg.
+func (p *Result) IsArg() bool {
+	return p.Flags&_OPTION_NON_OPTION_ARG > 0
 }
 
 // Return Optarg with default string
@@ -84,6 +251,90 @@ func (p *Result) WithDefault(arg string) string {
 type ParseResult struct {
 	Options []Result
 	Args    []string
+	Ordered []Result            // Both options and arguments in the original order
+	Named   map[string][]string // Arguments by the name, see [ParseResult.Assign]
+}
+
+// appends an option or a non-option argument to the result
+func (p *ParseResult) add(opt *Result) {
+	if opt.IsArg() {
+		p.Args = append(p.Args, opt.Optarg)
+	} else {
+		p.Options = append(p.Options, *opt)
+	}
+	p.Ordered = append(p.Ordered, *opt)
+}
+
+// appends the options supplied by the environment variables, if the option
+// was not found in the string array. Options without argument are enabled if
+// the variable is true as in [strconv.ParseBool].
+func (p *ParseResult) addEnv(options []Option) error {
+	for _, option := range options {
+		if option.Flags&OPTION_ALIAS > 0 || empty_str(option.Env) || p.HasOpt(option.Name()) {
+			continue
+		}
+		value, ok := os.LookupEnv(option.Env)
+		if !ok || value == "" {
+			continue
+		}
+		opt := &Result{Option: option, InputString: option.Env, Optarg: value, Index: -1, Source: SOURCE_ENV}
+		if empty_str(option.ArgName) {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return Error{Option: option, Message: ErrValue, Arg: value, Err: err}
+			} else if !enabled {
+				continue
+			}
+			opt.Optarg = ""
+		}
+		if err := setValue(opt); err != nil {
+			return err
+		}
+		p.Options = append(p.Options, *opt)
+	}
+	return nil
+}
+
+// Returns the required options which are not found in the result
+func (p *ParseResult) missing(options []Option) []Option {
+	var missing []Option
+	for _, option := range options {
+		if option.Flags&OPTION_ALIAS == 0 && option.Flags&OPTION_REQUIRED > 0 && !p.HasOpt(option.Name()) {
+			missing = append(missing, option)
+		}
+	}
+	return missing
+}
+
+// Returns [Error] with ErrRequired if the options with OPTION_REQUIRED are
+// not found in the result, or nil. Call it after [ParseResult.Merge] when
+// parsed with PARSE_DEFER_REQUIRED, so the configuration file can supply the
+// required options.
+//
+//	result, err := argp.ParseArgsFlags(options, args, argp.PARSE_DEFER_REQUIRED)
+//	config, err := argp.LoadConfig(options, path)
+//	err = result.Merge(config)
+//	err = result.CheckRequired(options)
+func (p *ParseResult) CheckRequired(options []Option) error {
+	return requiredError(p.missing(options))
+}
+
+// Returns [Error] with ErrRequired for the missing options, or nil if there
+// are no missing options. The Option of the error is the first one.
+func requiredError(missing []Option) error {
+	if len(missing) == 0 {
+		return nil
+	}
+	return Error{Option: missing[0], Message: ErrRequired, missing: joinLabels(missing)}
+}
+
+// appends the remaining arguments which starts from the index
+func (p *ParseResult) addRest(args []string, index int) {
+	for i, arg := range args {
+		opt := makeArg(arg)
+		opt.Index = index + i
+		p.add(opt)
+	}
 }
 
 // Check if option with given name was specified
@@ -114,20 +365,50 @@ func (p *ParseResult) GetOpts(name string) []*Result {
 
 // Parse string array
 func ParseArgs(options []Option, args []string) (ParseResult, error) {
-	parser := parser{options: options, args: args}
+	return ParseArgsFlags(options, args, 0)
+}
+
+// Parse string array with parse flags (PARSE_*)
+func ParseArgsFlags(options []Option, args []string, flags int) (ParseResult, error) {
 	var result ParseResult
+	if flags&PARSE_RESPONSE_FILE > 0 {
+		expanded, err := ExpandResponseFiles(args)
+		if err != nil {
+			return result, err
+		}
+		args = expanded
+	}
+	parser := parser{options: options, args: args, flags: parseFlags(flags)}
 	for {
 		opt, err := parser.next()
 		if err != nil || opt == nil {
-			result.Args = append(result.Args, parser.rest()...)
+			result.addRest(parser.rest(), parser.optidx)
+			if err == nil {
+				err = result.addEnv(options)
+			}
+			if err == nil && flags&PARSE_DEFER_REQUIRED == 0 {
+				err = result.CheckRequired(options)
+			}
 			return result, err
 		}
-		if opt.Flags&_OPTION_NON_OPTION_ARG > 0 {
-			result.Args = append(result.Args, opt.Optarg)
-		} else {
-			result.Options = append(result.Options, *opt)
+		result.add(opt)
+	}
+}
+
+// Returns a copy of the option table. Each option with a long name reads the
+// environment variable named after the prefix and the long name, e.g.
+// "APP_" and "dry-run" reads APP_DRY_RUN. Options which already have Env
+// are unchanged.
+func EnvPrefix(options []Option, prefix string) []Option {
+	table := make([]Option, len(options))
+	copy(table, options)
+	for i := range table {
+		opt := &table[i]
+		if opt.Flags&OPTION_ALIAS == 0 && empty_str(opt.Env) && !empty_str(opt.Long) {
+			opt.Env = prefix + strings.ToUpper(strings.ReplaceAll(opt.Long, "-", "_"))
 		}
 	}
+	return table
 }
 
 // Parse [os.Args] provided
@@ -141,6 +422,18 @@ type parser struct {
 	args    []string // user-provided argument list (readonly)
 	optidx  int      // parse index
 	subopt  int      // sub-index to parse short options
+	matched *Option  // the table entry of the last option found
+	flags   int      // parse flags
+}
+
+// resolves the parse flags depending on the environment
+func parseFlags(flags int) int {
+	if flags&PARSE_POSIXLY_CORRECT > 0 {
+		if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+			flags |= PARSE_REQUIRE_ORDER
+		}
+	}
+	return flags
 }
 
 // extracts one short option from the arg array
@@ -151,8 +444,9 @@ func (p *parser) short() (*Result, error) {
 	option := findShort(p.options, c)
 
 	if option == nil {
-		return nil, Error{Option{Short: c}, ErrInvalid}
+		return nil, Error{Option: Option{Short: c}, Message: ErrInvalid}
 	}
+	p.matched = option
 
 	cstr := string(c)
 
@@ -162,7 +456,7 @@ func (p *parser) short() (*Result, error) {
 			p.subopt = 0
 			p.optidx++
 		}
-		return &Result{*option, cstr, ""}, nil
+		return &Result{Option: *option, InputString: cstr, short: true}, nil
 	}
 	if option.Flags&OPTION_ARG_OPTIONAL == 0 {
 		optarg := string(runes[p.subopt+1:])
@@ -170,17 +464,17 @@ func (p *parser) short() (*Result, error) {
 		p.optidx++
 		if optarg == "" {
 			if p.optidx == len(p.args) {
-				return nil, Error{*option, ErrMissing}
+				return nil, Error{Option: *option, Message: ErrMissing}
 			}
 			optarg = p.args[p.optidx]
 			p.optidx++
 		}
-		return &Result{*option, cstr, optarg}, nil
+		return &Result{Option: *option, InputString: cstr, Optarg: optarg, short: true}, nil
 	} else {
 		optarg := string(runes[p.subopt+1:])
 		p.subopt = 0
 		p.optidx++
-		return &Result{*option, cstr, optarg}, nil
+		return &Result{Option: *option, InputString: cstr, Optarg: optarg, short: true}, nil
 	}
 }
 
@@ -198,36 +492,56 @@ func (p *parser) long() (*Result, error) {
 	}
 
 	option := findLong(p.options, long)
+	if option == nil && p.flags&PARSE_LONG_PREFIX > 0 && !empty_str(long) {
+		var candidates []string
+		option, candidates = findLongPrefix(p.options, long)
+		if len(candidates) > 0 {
+			return nil, Error{Option: Option{Long: long}, Message: ErrAmbiguous, candidates: strings.Join(candidates, "\n")}
+		}
+	}
 	if option == nil {
-		return nil, Error{Option{Long: long}, ErrInvalid}
+		return nil, Error{Option: Option{Long: long}, Message: ErrInvalid, candidates: strings.Join(suggestLong(p.options, long), "\n")}
 	}
+	p.matched = option
 
 	// consume one token here, after valid option was found
 	p.optidx++
 
 	if len(option.ArgName) == 0 { // No argument
 		if attached {
-			return nil, Error{*option, ErrTooMany}
+			return nil, Error{Option: *option, Message: ErrTooMany}
 		}
-		return &Result{*option, long, ""}, nil
+		return &Result{Option: *option, InputString: long}, nil
 	}
 
 	if option.Flags&OPTION_ARG_OPTIONAL == 0 {
 		if !attached {
 			if p.optidx >= len(p.args) {
-				return nil, Error{*option, ErrMissing}
+				return nil, Error{Option: *option, Message: ErrMissing}
 			}
 			optarg = p.args[p.optidx]
 			p.optidx++
 		}
-		return &Result{*option, long, optarg}, nil
+		return &Result{Option: *option, InputString: long, Optarg: optarg}, nil
 	} else {
-		return &Result{*option, long, optarg}, nil
+		return &Result{Option: *option, InputString: long, Optarg: optarg}, nil
 	}
 }
 
-// extracts one option from the arg array
+// extracts one option from the arg array, and stores the option argument
+// to the Value of the option
 func (p *parser) next() (*Result, error) {
+	index := p.optidx
+	opt, err := p.scan()
+	if err != nil || opt == nil {
+		return opt, err
+	}
+	opt.Index = index
+	return opt, setValue(opt)
+}
+
+// extracts one option or non-option from the arg array
+func (p *parser) scan() (*Result, error) {
 	if p.optidx >= len(p.args) {
 		return nil, nil
 	}
@@ -239,6 +553,9 @@ func (p *parser) next() (*Result, error) {
 	}
 
 	if len(arg) < 2 || arg[0] != '-' {
+		if p.flags&PARSE_REQUIRE_ORDER > 0 {
+			return nil, nil // leave the rest untouched
+		}
 		p.optidx++
 		return makeArg(arg), nil
 	}
@@ -276,6 +593,99 @@ func findLong(options []Option, long string) *Option {
 	return nil
 }
 
+// Returns the option whose long name starts with the prefix. If the prefix
+// matches several options, returns nil and all the matched long names.
+// Aliases of the same option are not ambiguous.
+func findLongPrefix(options []Option, prefix string) (*Option, []string) {
+	var pOptReal *Option
+	var pOptFound *Option
+	var names []string
+	var ambiguous bool
+	for i, option := range options {
+		if option.Flags&OPTION_ALIAS == 0 {
+			pOptReal = &options[i]
+		}
+		if pOptReal != nil && strings.HasPrefix(option.Long, prefix) {
+			if pOptFound != nil && pOptFound != pOptReal {
+				ambiguous = true
+			}
+			pOptFound = pOptReal
+			names = append(names, option.Long)
+		}
+	}
+	if ambiguous {
+		return nil, names
+	}
+	return pOptFound, nil
+}
+
+// Returns the long names similar to the given name, ordered by the edit
+// distance. Hidden options are never suggested.
+func suggestLong(options []Option, long string) []string {
+	type suggestion struct {
+		name     string
+		distance int
+	}
+	if empty_str(long) {
+		return nil
+	}
+	var suggestions []suggestion
+	var pOptReal *Option
+	threshold := len([]rune(long)) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+	for i, option := range options {
+		if option.Flags&OPTION_ALIAS == 0 {
+			pOptReal = &options[i]
+		}
+		if pOptReal == nil || pOptReal.Flags&OPTION_HIDDEN > 0 || empty_str(option.Long) {
+			continue
+		}
+		d := distance(long, option.Long)
+		if d <= threshold || strings.HasPrefix(option.Long, long) {
+			suggestions = append(suggestions, suggestion{option.Long, d})
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].distance < suggestions[j].distance
+	})
+	var names []string
+	for _, s := range suggestions {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+// Returns the Levenshtein distance of two strings
+func distance(a string, b string) int {
+	ra := []rune(a)
+	rb := []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j-1] + cost
+			if prev[j]+1 < curr[j] {
+				curr[j] = prev[j] + 1
+			}
+			if curr[j-1]+1 < curr[j] {
+				curr[j] = curr[j-1] + 1
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
 func findShort(options []Option, short rune) *Option {
 	var pOptReal *Option
 	for i, option := range options {
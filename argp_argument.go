@@ -0,0 +1,157 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// Mark this argument as optional
+	ARG_OPTIONAL = 0x1
+
+	// Mark this argument as variadic, which takes all the remaining
+	// arguments. Combined with ARG_OPTIONAL, it may take none.
+	ARG_VARIADIC = 0x2
+)
+
+// Argument describes a non-option argument (positional parameter). An array
+// of Argument assigns the non-option arguments to the names in order.
+//
+//	[]argp.Argument{
+//		{Name: "SRC", Doc: "source files", Flags: argp.ARG_VARIADIC},
+//		{Name: "DEST", Doc: "destination"},
+//	}
+type Argument struct {
+	Name  string // The name of the argument, e.g. "FILE"
+	Doc   string // Description
+	Flags int    // Argument flags (ARG_*)
+	Value Value  // Receives the converted argument. Set nil if unused.
+}
+
+// Returns the argument in the usage, e.g. "FILE", "[FILE]" or "FILE..."
+func (a *Argument) Usage() string {
+	usage := a.Name
+	if a.Flags&ARG_VARIADIC > 0 {
+		usage += "..."
+	}
+	if a.Flags&ARG_OPTIONAL > 0 {
+		usage = "[" + usage + "]"
+	}
+	return usage
+}
+
+// Returns the minimum number of the non-option arguments taken
+func (a *Argument) min() int {
+	if a.Flags&ARG_OPTIONAL > 0 {
+		return 0
+	}
+	return 1
+}
+
+// Assigns the non-option arguments to the arguments in order, and stores
+// them in Named. Required arguments are filled first, then the optional and
+// variadic arguments take the rest from the left. Each argument with a Value
+// calls Set with the assigned strings.
+//
+// Returns [Error] with ErrMissingArg and the name of the first argument not
+// filled in ArgumentName, or ErrTooManyArgs and the first argument left over
+// in Arg. The number of the arguments is checked before any Set is called. A
+// failure of Value.Set is ErrValue with both.
+func (p *ParseResult) Assign(arguments []Argument) error {
+	remain := 0
+	for i := range arguments {
+		remain += arguments[i].min()
+	}
+	if remain > len(p.Args) {
+		filled := 0
+		for i := range arguments {
+			if arguments[i].min() > 0 {
+				if filled == len(p.Args) {
+					return Error{Message: ErrMissingArg, ArgumentName: arguments[i].Name}
+				}
+				filled++
+			}
+		}
+	}
+
+	// assign all the arguments before Set, so nothing is set on error
+	assigned := make([][]string, len(arguments))
+	args := p.Args
+	for i := range arguments {
+		arg := &arguments[i]
+		remain -= arg.min()
+		take := 0
+		if arg.Flags&ARG_VARIADIC > 0 {
+			take = len(args) - remain
+		} else if len(args) > remain {
+			take = 1
+		}
+		assigned[i] = args[:take]
+		args = args[take:]
+	}
+	if len(args) > 0 {
+		return Error{Message: ErrTooManyArgs, Arg: args[0]}
+	}
+
+	p.Named = make(map[string][]string)
+	for i := range arguments {
+		arg := &arguments[i]
+		p.Named[arg.Name] = append(p.Named[arg.Name], assigned[i]...)
+		if arg.Value == nil {
+			continue
+		}
+		for _, value := range assigned[i] {
+			if err := arg.Value.Set(value); err != nil {
+				return Error{Message: ErrValue, Arg: value, ArgumentName: arg.Name, Err: err}
+			}
+		}
+	}
+	return nil
+}
+
+// Returns the first non-option argument assigned to the name, or empty
+// string if not found
+func (p *ParseResult) GetArg(name string) string {
+	if args := p.Named[name]; len(args) > 0 {
+		return args[0]
+	}
+	return ""
+}
+
+// Returns all the non-option arguments assigned to the name
+func (p *ParseResult) GetArgs(name string) []string {
+	return p.Named[name]
+}
+
+// Returns the usage of the arguments joined with a space, which can be
+// passed to [PrintUsage] as arg.
+//
+//	SRC... DEST [MODE]
+func FormatArgsDoc(arguments []Argument) string {
+	var list []string
+	for i := range arguments {
+		list = append(list, arguments[i].Usage())
+	}
+	return strings.Join(list, " ")
+}
+
+// Prints the "Arguments:" section of the help message, which lists the
+// arguments and their descriptions like the option list.
+func PrintArgList(w io.Writer, arguments []Argument) {
+	f := HelpFormatEnv()
+	fmt.Fprintln(w, "Arguments:")
+	for _, row := range argumentRows(arguments, &f) {
+		fmt.Fprint(w, row.Text)
+	}
+}
+
+// Returns the rows of the arguments in the help message
+func argumentRows(arguments []Argument, f *HelpFormat) []HelpRow {
+	var rows []HelpRow
+	for i := range arguments {
+		left := spaces(f.ShortOptCol) + arguments[i].Usage()
+		rows = append(rows, HelpRow{Left: left, Doc: arguments[i].Doc, Text: formatRow(left, arguments[i].Doc, f)})
+	}
+	return rows
+}
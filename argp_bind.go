@@ -0,0 +1,259 @@
+package argp
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bind parses the string array with the option table built from the struct
+// tags of v, and stores the converted option arguments into the fields.
+// v must be a pointer to a struct. The following tags are recognized:
+//
+//	short:"o"           the short option name
+//	long:"output"       the long option name
+//	argname:"<file>"    the name of argument (ignored for bool fields)
+//	doc:"output file"   the description
+//	default:"a.out"     the value used if the option is not specified
+//	env:"APP_OUTPUT"    the environment variable used if the option is absent
+//	flags:"hidden"      comma separated list of "hidden", "optional", "required"
+//
+// Supported field types are string, bool, integers, floats, [time.Duration]
+// and slices of them. Slice fields collect all occurrences of the option.
+// Fields implementing [Value] are set by the parser. Fields without short
+// and long tags are ignored.
+func Bind(v any, args []string) (ParseResult, error) {
+	fields, err := bindFields(v)
+	if err != nil {
+		return ParseResult{}, err
+	}
+	for _, f := range fields {
+		if err := f.setDefault(); err != nil {
+			return ParseResult{}, err
+		}
+	}
+
+	result, err := ParseArgs(bindOptions(fields), args)
+	if err != nil {
+		return result, err
+	}
+
+	for _, f := range fields {
+		opts := result.GetOpts(f.option.Name())
+		if len(opts) == 0 || f.option.Value != nil {
+			continue
+		}
+		if f.value.Kind() == reflect.Slice {
+			f.value.Set(reflect.MakeSlice(f.value.Type(), 0, len(opts)))
+		}
+		for _, opt := range opts {
+			if err := f.setOptarg(opt.Optarg); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// Bind [os.Args] provided
+func BindArgs(v any) (ParseResult, error) {
+	return Bind(v, os.Args[1:])
+}
+
+// Returns the option table built from the struct tags of v, which can be
+// passed to [PrintOptList] to print the help message. See [Bind] for the
+// tags.
+func StructOptions(v any) ([]Option, error) {
+	fields, err := bindFields(v)
+	if err != nil {
+		return nil, err
+	}
+	return bindOptions(fields), nil
+}
+
+// bindField is a pair of an option and the struct field it is bound to
+type bindField struct {
+	option Option
+	value  reflect.Value
+	def    string
+}
+
+func (f *bindField) setDefault() error {
+	if empty_str(f.def) {
+		return nil
+	} else if f.option.Value != nil {
+		if err := f.option.Value.Set(f.def); err != nil {
+			return Error{Option: f.option, Message: ErrValue, Arg: f.def, Err: err}
+		}
+		return nil
+	} else {
+		return f.set(f.def)
+	}
+}
+
+// converts the option argument and stores it into the field
+func (f *bindField) setOptarg(optarg string) error {
+	if empty_str(f.option.ArgName) {
+		return f.set("true")
+	} else if f.option.Flags&OPTION_ARG_OPTIONAL > 0 && optarg == "" {
+		return f.setDefault()
+	} else {
+		return f.set(optarg)
+	}
+}
+
+// converts the string and stores it into the field. Slice fields append it.
+func (f *bindField) set(arg string) error {
+	target := f.value
+	if target.Kind() == reflect.Slice {
+		elem := reflect.New(target.Type().Elem()).Elem()
+		if err := convert(elem, arg); err != nil {
+			return Error{Option: f.option, Message: ErrValue, Arg: arg, Err: err}
+		}
+		target.Set(reflect.Append(target, elem))
+		return nil
+	}
+	if err := convert(target, arg); err != nil {
+		return Error{Option: f.option, Message: ErrValue, Arg: arg, Err: err}
+	}
+	return nil
+}
+
+func bindOptions(fields []bindField) []Option {
+	options := make([]Option, 0, len(fields))
+	for _, f := range fields {
+		options = append(options, f.option)
+	}
+	return options
+}
+
+func bindFields(v any) ([]bindField, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("argp: Bind requires a pointer to a struct, got %T", v)
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	var fields []bindField
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		short := sf.Tag.Get("short")
+		long := sf.Tag.Get("long")
+		if !sf.IsExported() || (short == "" && long == "") {
+			continue
+		}
+
+		option := Option{
+			Long:    long,
+			ArgName: sf.Tag.Get("argname"),
+			Doc:     sf.Tag.Get("doc"),
+			Env:     sf.Tag.Get("env"),
+		}
+		if value, ok := rv.Field(i).Addr().Interface().(Value); ok {
+			option.Value = value
+		} else if !convertible(sf.Type) {
+			return nil, fmt.Errorf("argp: unsupported type %s of field %s", sf.Type, sf.Name)
+		}
+		if runes := []rune(short); len(runes) == 1 {
+			option.Short = runes[0]
+		} else if len(runes) > 1 {
+			return nil, fmt.Errorf("argp: invalid short name %q of field %s", short, sf.Name)
+		}
+		for _, flag := range strings.Split(sf.Tag.Get("flags"), ",") {
+			switch strings.TrimSpace(flag) {
+			case "":
+			case "hidden":
+				option.Flags |= OPTION_HIDDEN
+			case "optional":
+				option.Flags |= OPTION_ARG_OPTIONAL
+			case "required":
+				option.Flags |= OPTION_REQUIRED
+			default:
+				return nil, fmt.Errorf("argp: unknown flag %q of field %s", flag, sf.Name)
+			}
+		}
+		if option.Value == nil && isBool(sf.Type) {
+			option.ArgName = ""
+		} else if empty_str(option.ArgName) {
+			option.ArgName = "ARG"
+		}
+
+		fields = append(fields, bindField{
+			option: option,
+			value:  rv.Field(i),
+			def:    sf.Tag.Get("default"),
+		})
+	}
+	return fields, nil
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bool and []bool fields take no argument
+func isBool(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+func convertible(t reflect.Type) bool {
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// converts the string to the type of v, and stores it
+func convert(v reflect.Value, arg string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(arg)
+		if err == nil {
+			v.SetInt(int64(d))
+		}
+		return err
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(arg)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(arg)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(arg, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(arg, 0, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(arg, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
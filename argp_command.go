@@ -0,0 +1,209 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Command represents a node of the command tree. Each command has its own
+// option table, and may have child commands (subcommands).
+//
+//	tool [global options] build [build options] ARGS
+type Command struct {
+	Name     string    // The command name
+	Aliases  []string  // Alternative names of the command
+	Doc      string    // Single line description for the command list
+	Options  []Option  // The option table of this command
+	Commands []Command // Subcommands
+	Flags    int       // Parse flags (PARSE_*), see [ParseCommand]
+}
+
+// Returns true if the name or one of the aliases equals the argument
+func (c *Command) Is(name string) bool {
+	if empty_str(name) {
+		return false
+	}
+	if name == c.Name {
+		return true
+	}
+	for _, alias := range c.Aliases {
+		if name == alias {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandResult is the result of parsing with a command tree. Path is the
+// list of selected commands from the root, and Results holds the options
+// and arguments parsed for each level of the Path.
+type CommandResult struct {
+	Path    []*Command
+	Results []ParseResult
+}
+
+// Returns the last selected command
+func (c *CommandResult) Command() *Command {
+	if len(c.Path) == 0 {
+		return nil
+	}
+	return c.Path[len(c.Path)-1]
+}
+
+// Returns the ParseResult of the last selected command
+func (c *CommandResult) Result() *ParseResult {
+	if len(c.Results) == 0 {
+		return nil
+	}
+	return &c.Results[len(c.Results)-1]
+}
+
+// Returns the names of the selected commands, joined with a space
+func (c *CommandResult) Name() string {
+	return commandName(c.Path)
+}
+
+// Parse string array with a command tree. The first non-option argument
+// selects a subcommand if the current command has any. Options of the parent
+// commands are still accepted after the subcommand name, and are stored in
+// the ParseResult of the command declaring them.
+//
+// The parse flags of the commands in the path are combined, so the flags of
+// a parent apply to the subcommands. PARSE_RESPONSE_FILE and
+// PARSE_DEFER_REQUIRED apply to the whole arguments, and are read only from
+// the root.
+func ParseCommand(root *Command, args []string) (CommandResult, error) {
+	result := CommandResult{
+		Path:    []*Command{root},
+		Results: []ParseResult{{}},
+	}
+	if root.Flags&PARSE_RESPONSE_FILE > 0 {
+		expanded, err := ExpandResponseFiles(args)
+		if err != nil {
+			return result, err
+		}
+		args = expanded
+	}
+	table, owners := commandOptions(result.Path)
+	parser := parser{options: table, args: args, flags: commandFlags(result.Path)}
+	for {
+		opt, err := parser.next()
+		leaf := result.Result()
+		if err != nil || opt == nil {
+			leaf.addRest(parser.rest(), parser.optidx)
+			for level := 0; err == nil && level < len(result.Path); level++ {
+				err = result.Results[level].addEnv(result.Path[level].Options)
+			}
+			if err == nil && root.Flags&PARSE_DEFER_REQUIRED == 0 {
+				var missing []Option
+				for level := range result.Path {
+					missing = append(missing, result.Results[level].missing(result.Path[level].Options)...)
+				}
+				err = requiredError(missing)
+			}
+			return result, err
+		}
+		if !opt.IsArg() {
+			level := owners[indexOf(table, parser.matched)]
+			result.Results[level].add(opt)
+		} else if cmd := result.Command(); len(cmd.Commands) > 0 {
+			sub := findCommand(cmd.Commands, opt.Optarg)
+			if sub == nil {
+				return result, Error{Message: ErrCommand, Arg: opt.Optarg}
+			}
+			result.Path = append(result.Path, sub)
+			result.Results = append(result.Results, ParseResult{})
+			table, owners = commandOptions(result.Path)
+			parser.options = table
+			parser.flags = commandFlags(result.Path)
+		} else {
+			leaf.add(opt)
+		}
+	}
+}
+
+// Prints the help message of the last command in the path. The usage line
+// contains the names of all commands in the path, and the subcommands are
+// listed after the options.
+func PrintCommandUsage(w io.Writer, path []*Command, arg string) {
+	if len(path) == 0 {
+		return
+	}
+	cmd := path[len(path)-1]
+	PrintUsage(w, cmd.Options, commandName(path), arg)
+	if len(cmd.Commands) > 0 {
+		if len(cmd.Options) > 0 {
+			fmt.Fprintln(w)
+		}
+		PrintCommandList(w, cmd.Commands)
+	}
+}
+
+// Prints the list of the commands with their descriptions
+func PrintCommandList(w io.Writer, commands []Command) {
+	f := HelpFormatEnv()
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		left := " " + strings.Join(append([]string{cmd.Name}, cmd.Aliases...), ", ")
+		printRow(w, left, cmd.Doc, &f)
+	}
+}
+
+// Returns the option table for the command path, and the level of the
+// command which owns each option. The options of the deeper commands come
+// first, so they take precedence over the options of the parents.
+func commandOptions(path []*Command) ([]Option, []int) {
+	var table []Option
+	var owners []int
+	for level := len(path) - 1; level >= 0; level-- {
+		for _, opt := range path[level].Options {
+			table = append(table, opt)
+			owners = append(owners, level)
+		}
+	}
+	return table, owners
+}
+
+// Returns the parse flags of the commands in the path combined. The
+// subcommand name is not a non-option argument, so PARSE_REQUIRE_ORDER is
+// ignored while the last command has subcommands.
+func commandFlags(path []*Command) int {
+	flags := 0
+	for _, cmd := range path {
+		flags |= cmd.Flags
+	}
+	flags = parseFlags(flags)
+	if len(path[len(path)-1].Commands) > 0 {
+		flags &^= PARSE_REQUIRE_ORDER
+	}
+	return flags
+}
+
+func commandName(path []*Command) string {
+	var names []string
+	for _, cmd := range path {
+		if !empty_str(cmd.Name) {
+			names = append(names, cmd.Name)
+		}
+	}
+	return strings.Join(names, " ")
+}
+
+func findCommand(commands []Command, name string) *Command {
+	for i := range commands {
+		if commands[i].Is(name) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func indexOf(options []Option, option *Option) int {
+	for i := range options {
+		if &options[i] == option {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,205 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compOption is an option and its aliases in the completion scripts
+type compOption struct {
+	shorts   []rune
+	longs    []string
+	argName  string
+	optional bool // the argument is optional
+	doc      string
+}
+
+// Returns true if the option takes the argument
+func (c *compOption) hasArg() bool {
+	return !empty_str(c.argName)
+}
+
+// Returns the options to complete. Hidden options and headers are skipped.
+func completionOptions(options []Option) []compOption {
+	var list []compOption
+	for _, group := range newHelpModel(options, DefaultHelpFormat()).Groups {
+		for _, row := range group.Rows {
+			opt := compOption{
+				argName:  strings.TrimSpace(row.Option.ArgName),
+				optional: row.Option.Flags&OPTION_ARG_OPTIONAL > 0,
+			}
+			opt.doc, _, _ = strings.Cut(strings.TrimSpace(row.Option.Doc), "\n")
+			for _, o := range append([]*Option{row.Option}, row.Aliases...) {
+				if !empty_rune(o.Short) {
+					opt.shorts = append(opt.shorts, o.Short)
+				}
+				if !empty_str(o.Long) {
+					opt.longs = append(opt.longs, o.Long)
+				}
+			}
+			list = append(list, opt)
+		}
+	}
+	return list
+}
+
+// Returns the name of the shell function for the command
+func completionFunc(cmd string) string {
+	return "_" + strings.Map(func(c rune) rune {
+		if c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') {
+			return c
+		}
+		return '_'
+	}, cmd)
+}
+
+// Returns the string quoted for bash and zsh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Prints the bash completion script for the command. The option names are
+// completed if the word starts with '-', and the files are completed for the
+// arguments of the options and the non-option arguments. Write the script to
+// a file, and load it by "source cmd.bash" in the shell.
+//
+//	f, err := os.Create("cmd.bash")
+//	argp.PrintBashCompletion(f, options, "cmd")
+func PrintBashCompletion(w io.Writer, options []Option, cmd string) {
+	var names []string
+	var argNames []string
+	for _, opt := range completionOptions(options) {
+		for _, c := range opt.shorts {
+			names = append(names, "-"+string(c))
+			if opt.hasArg() && !opt.optional {
+				argNames = append(argNames, shellQuote("-"+string(c)))
+			}
+		}
+		for _, long := range opt.longs {
+			names = append(names, "--"+long)
+			if opt.hasArg() && !opt.optional {
+				argNames = append(argNames, shellQuote("--"+long))
+			}
+			if opt.hasArg() {
+				argNames = append(argNames, shellQuote("--"+long+"="))
+			}
+		}
+	}
+
+	fn := completionFunc(cmd)
+	fmt.Fprintf(w, "# bash completion for %s\n\n", cmd)
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    local prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w, `    if [[ "$cur" == "=" ]]; then`)
+	fmt.Fprintln(w, `        cur=""`)
+	fmt.Fprintln(w, `        prev="$prev="`)
+	fmt.Fprintln(w, `    elif [[ "$prev" == "=" && $COMP_CWORD -ge 2 ]]; then`)
+	fmt.Fprintln(w, `        prev="${COMP_WORDS[COMP_CWORD-2]}="`)
+	fmt.Fprintln(w, `    fi`)
+	if len(argNames) > 0 {
+		fmt.Fprintln(w, `    case "$prev" in`)
+		fmt.Fprintf(w, "        %s)\n", strings.Join(argNames, "|"))
+		fmt.Fprintln(w, `            COMPREPLY=($(compgen -f -- "$cur"))`)
+		fmt.Fprintln(w, `            return`)
+		fmt.Fprintln(w, `            ;;`)
+		fmt.Fprintln(w, `    esac`)
+	}
+	fmt.Fprintln(w, `    if [[ "$cur" == -* ]]; then`)
+	fmt.Fprintf(w, "        COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(names, " ")))
+	fmt.Fprintln(w, `        return`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w, `    COMPREPLY=($(compgen -f -- "$cur"))`)
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, shellQuote(cmd))
+}
+
+// Prints the zsh completion script for the command, which uses _arguments
+// with the descriptions of the options. The script can be placed in $fpath
+// as "_cmd", or sourced.
+func PrintZshCompletion(w io.Writer, options []Option, cmd string) {
+	fn := completionFunc(cmd)
+	fmt.Fprintf(w, "#compdef %s\n", cmd)
+	fmt.Fprintf(w, "compdef %s %s\n\n", fn, shellQuote(cmd))
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintln(w, "    _arguments -s \\")
+	for _, opt := range completionOptions(options) {
+		var all []string
+		for _, c := range opt.shorts {
+			all = append(all, "-"+string(c))
+		}
+		for _, long := range opt.longs {
+			all = append(all, "--"+long)
+		}
+		exclusion := ""
+		if len(all) > 1 {
+			exclusion = "(" + strings.Join(all, " ") + ")"
+		}
+		doc := ""
+		if !empty_str(opt.doc) {
+			doc = "[" + zshEscape(opt.doc) + "]"
+		}
+		arg := ""
+		if opt.hasArg() && opt.optional {
+			arg = "::" + zshEscape(opt.argName) + ":_files"
+		} else if opt.hasArg() {
+			arg = ":" + zshEscape(opt.argName) + ":_files"
+		}
+
+		for _, name := range all {
+			long := strings.HasPrefix(name, "--")
+			suffix := ""
+			if opt.hasArg() && opt.optional && long {
+				suffix = "=-" // the argument must be in the same word
+			} else if opt.hasArg() && opt.optional {
+				suffix = "-"
+			} else if opt.hasArg() && long {
+				suffix = "="
+			} else if opt.hasArg() {
+				suffix = "+"
+			}
+			fmt.Fprintf(w, "        %s \\\n", shellQuote(exclusion+name+suffix+doc+arg))
+		}
+	}
+	fmt.Fprintln(w, "        '*:file:_files'")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "if [ \"$funcstack[1]\" = %s ]; then\n", shellQuote(fn))
+	fmt.Fprintf(w, "    %s \"$@\"\n", fn)
+	fmt.Fprintln(w, "fi")
+}
+
+// Returns the text escaped for the _arguments spec
+func zshEscape(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "[", "\\[", "]", "\\]", ":", "\\:").Replace(s)
+}
+
+// Prints the fish completion script for the command. Options taking an
+// argument require the next word, which is completed as a file.
+func PrintFishCompletion(w io.Writer, options []Option, cmd string) {
+	fmt.Fprintf(w, "# fish completion for %s\n", cmd)
+	for _, opt := range completionOptions(options) {
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "complete -c %s", fishQuote(cmd))
+		for _, c := range opt.shorts {
+			fmt.Fprintf(&buf, " -s %s", fishQuote(string(c)))
+		}
+		for _, long := range opt.longs {
+			fmt.Fprintf(&buf, " -l %s", fishQuote(long))
+		}
+		if opt.hasArg() && !opt.optional {
+			buf.WriteString(" -r")
+		}
+		if !empty_str(opt.doc) {
+			fmt.Fprintf(&buf, " -d %s", fishQuote(opt.doc))
+		}
+		fmt.Fprintln(w, buf.String())
+	}
+}
+
+// Returns the string quoted for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
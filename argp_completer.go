@@ -0,0 +1,231 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The hidden argument which runs the completion. The shell scripts printed
+// by [PrintCompletionShim] call the program as:
+//
+//	cmd __complete [words...] CURRENT
+const CompleteCommand = "__complete"
+
+// Completer returns the candidates for the word being completed. The
+// candidates not starting with the prefix are dropped.
+type Completer interface {
+	Complete(prefix string) []string
+}
+
+// CompleteFunc is a function used as a [Completer]. Functions are not
+// comparable, so an [Option] holding a CompleteFunc panics when compared
+// with ==. Use a pointer to a struct if the options are compared.
+type CompleteFunc func(prefix string) []string
+
+// Returns the result of the function
+func (f CompleteFunc) Complete(prefix string) []string {
+	return f(prefix)
+}
+
+// wordsCompleter completes the fixed words
+type wordsCompleter struct {
+	words []string
+}
+
+// Returns the words
+func (c *wordsCompleter) Complete(prefix string) []string {
+	return c.words
+}
+
+// Returns the [Completer] of the fixed words. The Completer is a pointer, so
+// the [Option] holding it stays comparable.
+func CompleteWords(words ...string) Completer {
+	return &wordsCompleter{words}
+}
+
+// Returns the candidates for the last word, which is the word being completed
+// and may be empty. The other words are parsed by the same parser as
+// [ParseArgs], so the cursor is determined as one of:
+//
+//	--name, -n        the option names, if the word starts with '-'
+//	--name=ARG        the argument of the long option
+//	-abn, -abnARG     the argument of the option, after grouped short options
+//	-n ARG            the argument, after the option requiring one
+//	ARG               the non-option argument, completed by args
+//
+// The arguments are completed by the Completer of the option, or the Value
+// of the option if it implements [Completer]. args may be nil. The Values
+// of the options are not set.
+func Complete(options []Option, words []string, args Completer) []string {
+	return CompleteFlags(options, words, 0, args)
+}
+
+// Returns the candidates for the last word with parse flags (PARSE_*), see
+// [Complete].
+func CompleteFlags(options []Option, words []string, flags int, args Completer) []string {
+	cur := ""
+	if len(words) > 0 {
+		cur = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+
+	p := parser{options: options, args: words, flags: parseFlags(flags)}
+	for {
+		index := p.optidx
+		opt, err := p.scan()
+		if e, ok := err.(Error); ok && e.Message == ErrMissing && p.optidx >= len(words) {
+			// the last word is an option requiring the argument
+			return completeArg(p.matched, cur, "")
+		} else if err != nil {
+			// skip the invalid word
+			if p.optidx == index {
+				p.optidx++
+			}
+			p.subopt = 0
+		} else if opt == nil && index < len(words) {
+			// the rest are non-option arguments after "--"
+			return completeWith(args, cur, "")
+		} else if opt == nil {
+			break
+		}
+	}
+
+	if len(cur) < 2 || cur[0] != '-' {
+		if cur == "-" {
+			return completeNames(options, cur)
+		}
+		return completeWith(args, cur, "")
+	}
+	if cur[:2] == "--" {
+		name, arg, attached := strings.Cut(cur[2:], "=")
+		if !attached {
+			return completeNames(options, cur)
+		}
+		option := findLong(options, name)
+		if option == nil && p.flags&PARSE_LONG_PREFIX > 0 && !empty_str(name) {
+			option, _ = findLongPrefix(options, name)
+		}
+		if option == nil || empty_str(option.ArgName) {
+			return nil
+		}
+		return completeArg(option, arg, cur[:len(cur)-len(arg)])
+	}
+
+	// grouped short options, which may end with the argument
+	p = parser{options: options, args: []string{cur}, flags: p.flags, subopt: 1}
+	for p.optidx == 0 {
+		opt, err := p.short()
+		if err != nil {
+			if e, ok := err.(Error); ok && e.Message == ErrMissing {
+				return []string{cur} // the argument follows in the next word
+			}
+			return nil
+		}
+		if !empty_str(opt.ArgName) {
+			return completeArg(p.matched, opt.Optarg, cur[:len(cur)-len(opt.Optarg)])
+		}
+	}
+	return []string{cur}
+}
+
+// Returns the argument candidates of the option, prepended by the prefix
+func completeArg(option *Option, arg string, prefix string) []string {
+	if option.Completer != nil {
+		return completeWith(option.Completer, arg, prefix)
+	}
+	if c, ok := option.Value.(Completer); ok {
+		return completeWith(c, arg, prefix)
+	}
+	return nil
+}
+
+// Returns the candidates starting with the word, prepended by the prefix
+func completeWith(c Completer, word string, prefix string) []string {
+	if c == nil {
+		return nil
+	}
+	var candidates []string
+	for _, candidate := range c.Complete(word) {
+		if strings.HasPrefix(candidate, word) {
+			candidates = append(candidates, prefix+candidate)
+		}
+	}
+	return candidates
+}
+
+// Returns the option names starting with the word. Hidden options are
+// skipped.
+func completeNames(options []Option, word string) []string {
+	var names []string
+	var pOptReal *Option
+	for i, opt := range options {
+		if opt.Flags&OPTION_ALIAS == 0 {
+			pOptReal = &options[i]
+		}
+		if pOptReal == nil || pOptReal.Flags&OPTION_HIDDEN > 0 {
+			continue
+		}
+		if !empty_rune(opt.Short) && strings.HasPrefix("-"+string(opt.Short), word) {
+			names = append(names, "-"+string(opt.Short))
+		}
+		if !empty_str(opt.Long) && strings.HasPrefix("--"+opt.Long, word) {
+			names = append(names, "--"+opt.Long)
+		}
+	}
+	return names
+}
+
+// Prints the shell script which completes the command by calling it with
+// [CompleteCommand]. The shell is one of "bash", "zsh" or "fish". Files are
+// completed if the command returns no candidates. Returns [Error] for an
+// unknown shell.
+func PrintCompletionShim(w io.Writer, shell string, cmd string) error {
+	fn := completionFunc(cmd)
+	switch shell {
+	case "bash":
+		fmt.Fprintf(w, "# bash completion for %s\n\n", cmd)
+		fmt.Fprintf(w, "%s() {\n", fn)
+		fmt.Fprintln(w, `    local words cur`)
+		fmt.Fprintln(w, `    read -r -a words <<< "${COMP_LINE:0:COMP_POINT}"`)
+		fmt.Fprintln(w, `    if [[ "${COMP_LINE:COMP_POINT-1:1}" == [[:space:]] ]]; then`)
+		fmt.Fprintln(w, `        words+=("")`)
+		fmt.Fprintln(w, `    fi`)
+		fmt.Fprintln(w, `    cur="${words[${#words[@]}-1]}"`)
+		fmt.Fprintln(w, `    local IFS=$'\n'`)
+		fmt.Fprintf(w, "    COMPREPLY=($(%s %s \"${words[@]:1}\" 2>/dev/null))\n", shellQuote(cmd), CompleteCommand)
+		fmt.Fprintln(w, `    if [[ "$cur" == *=* ]]; then`)
+		fmt.Fprintln(w, `        COMPREPLY=("${COMPREPLY[@]#"${cur%%=*}="}")`)
+		fmt.Fprintln(w, `    fi`)
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "complete -o default -F %s %s\n", fn, shellQuote(cmd))
+	case "zsh":
+		fmt.Fprintf(w, "#compdef %s\n", cmd)
+		fmt.Fprintf(w, "compdef %s %s\n\n", fn, shellQuote(cmd))
+		fmt.Fprintf(w, "%s() {\n", fn)
+		fmt.Fprintln(w, `    local -a candidates`)
+		fmt.Fprintf(w, "    candidates=(\"${(@f)$(%s %s \"${(@)words[2,CURRENT]}\" 2>/dev/null)}\")\n", shellQuote(cmd), CompleteCommand)
+		fmt.Fprintln(w, `    if [[ -n "${candidates[1]}" ]]; then`)
+		fmt.Fprintln(w, `        compadd -- "${candidates[@]}"`)
+		fmt.Fprintln(w, `    else`)
+		fmt.Fprintln(w, `        _files`)
+		fmt.Fprintln(w, `    fi`)
+		fmt.Fprintln(w, "}")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "if [ \"$funcstack[1]\" = %s ]; then\n", shellQuote(fn))
+		fmt.Fprintf(w, "    %s \"$@\"\n", fn)
+		fmt.Fprintln(w, "fi")
+	case "fish":
+		fmt.Fprintf(w, "# fish completion for %s\n\n", cmd)
+		fmt.Fprintf(w, "function %s_complete\n", fn)
+		fmt.Fprintln(w, `    set -l tokens (commandline -opc) (commandline -ct)`)
+		fmt.Fprintf(w, "    %s %s $tokens[2..-1] 2>/dev/null\n", fishQuote(cmd), CompleteCommand)
+		fmt.Fprintln(w, "end")
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "complete -c %s -a '(%s_complete)'\n", fishQuote(cmd), fn)
+	default:
+		return Error{Message: ErrValue, Arg: shell}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package argp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Reads the configuration file, and returns the options found in the file.
+// Files with ".json" extension are read as JSON, otherwise as INI. The keys
+// are the long names of the options in the option table. Merge the result
+// into the command-line result with [ParseResult.Merge]. Parse with
+// PARSE_DEFER_REQUIRED if the file may supply the required options.
+//
+//	result, err := argp.ParseArgsFlags(options, args, argp.PARSE_DEFER_REQUIRED)
+//	path := result.GetOpt("config").WithDefault("app.ini")
+//	config, err := argp.LoadConfig(options, path)
+//	err = result.Merge(config)
+//	err = result.CheckRequired(options)
+func LoadConfig(options []Option, path string) (ParseResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return ParseResult{}, err
+	}
+	defer f.Close()
+	return ReadConfig(options, f, path)
+}
+
+// Reads the configuration from the reader. The name is used to select the
+// format and to report errors. See [LoadConfig].
+//
+// The INI format is a list of "key = value" lines. Lines starting with '#'
+// or ';' are comments. A key without value enables the option which takes no
+// argument. Keys in a [section] are looked up as "section-key". Repeated
+// keys supply the option multiple times.
+//
+// The JSON format is an object of strings, numbers and booleans. Arrays
+// supply the option multiple times, and null is ignored.
+func ReadConfig(options []Option, r io.Reader, name string) (ParseResult, error) {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return readJSONConfig(options, r, name)
+	}
+	return readINIConfig(options, r, name)
+}
+
+// Merges the options of other, which are not found in p. Typically p is the
+// command-line result and other is the configuration file. The Values of the
+// merged options are set.
+func (p *ParseResult) Merge(other ParseResult) error {
+	given := p.Options
+	for i := range other.Options {
+		opt := &other.Options[i]
+		if hasOption(given, opt.Name()) {
+			continue
+		}
+		if err := setValue(opt); err != nil {
+			return err
+		}
+		p.Options = append(p.Options, *opt)
+	}
+	return nil
+}
+
+func hasOption(results []Result, name string) bool {
+	for _, opt := range results {
+		if opt.Is(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func readINIConfig(options []Option, r io.Reader, name string) (ParseResult, error) {
+	var result ParseResult
+	var section string
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' || text[0] == ';' {
+			continue
+		}
+		if text[0] == '[' {
+			if text[len(text)-1] != ']' {
+				return result, Error{Message: ErrSyntax, Arg: text, File: name, Line: line}
+			}
+			section = strings.TrimSpace(text[1 : len(text)-1])
+			continue
+		}
+
+		key, value, found := strings.Cut(text, "=")
+		key = strings.TrimSpace(key)
+		value = unquote(strings.TrimSpace(value))
+		if section != "" {
+			key = section + "-" + key
+		}
+		opt, err := configOption(options, key, value, found)
+		if err != nil {
+			return result, withPosition(err, name, line)
+		}
+		if opt != nil {
+			result.Options = append(result.Options, *opt)
+		}
+	}
+	return result, scanner.Err()
+}
+
+func readJSONConfig(options []Option, r io.Reader, name string) (ParseResult, error) {
+	var result ParseResult
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return result, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	syntaxError := func(text string, line int) error {
+		return Error{Message: ErrSyntax, Arg: text, File: name, Line: line}
+	}
+	decodeError := func(err error) error {
+		offset := dec.InputOffset()
+		if e, ok := err.(*json.SyntaxError); ok {
+			offset = e.Offset
+		}
+		return syntaxError(err.Error(), lineAt(offset))
+	}
+
+	if tok, err := dec.Token(); err != nil {
+		return result, decodeError(err)
+	} else if tok != json.Delim('{') {
+		return result, syntaxError("expected an object", lineAt(dec.InputOffset()))
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return result, decodeError(err)
+		}
+		key, _ := tok.(string)
+		line := lineAt(dec.InputOffset())
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return result, decodeError(err)
+		}
+
+		var values []json.RawMessage
+		if err := json.Unmarshal(raw, &values); err != nil {
+			values = []json.RawMessage{raw}
+		}
+		for _, v := range values {
+			if string(v) == "null" {
+				continue
+			}
+			if v[0] == '{' || v[0] == '[' {
+				return result, syntaxError(fmt.Sprintf("expected a string, number or boolean: %s", key), line)
+			}
+			var value string
+			if err := json.Unmarshal(v, &value); err != nil {
+				value = string(v)
+			}
+			opt, err := configOption(options, key, value, true)
+			if err != nil {
+				return result, withPosition(err, name, line)
+			}
+			if opt != nil {
+				result.Options = append(result.Options, *opt)
+			}
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return result, decodeError(err)
+	}
+	return result, nil
+}
+
+// Returns the Result of the key and value found in the configuration file.
+// Returns nil if the option which takes no argument is disabled.
+func configOption(options []Option, key string, value string, hasValue bool) (*Result, error) {
+	option := findLong(options, key)
+	if option == nil || empty_str(key) {
+		return nil, Error{Option: Option{Long: key}, Message: ErrInvalid, candidates: strings.Join(suggestLong(options, key), "\n")}
+	}
+	opt := &Result{Option: *option, InputString: key, Optarg: value, Index: -1, Source: SOURCE_CONFIG}
+	if !empty_str(option.ArgName) {
+		if !hasValue && option.Flags&OPTION_ARG_OPTIONAL == 0 {
+			return nil, Error{Option: *option, Message: ErrMissing}
+		}
+		return opt, nil
+	}
+	if hasValue {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, Error{Option: *option, Message: ErrValue, Arg: value, Err: err}
+		} else if !enabled {
+			return nil, nil
+		}
+	}
+	opt.Optarg = ""
+	return opt, nil
+}
+
+func withPosition(err error, name string, line int) error {
+	if e, ok := err.(Error); ok {
+		e.File = name
+		e.Line = line
+		return e
+	}
+	return err
+}
+
+// removes the surrounding quotes
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,127 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// The kind of the Constraint
+	CONSTRAINT_EXCLUSIVE    = 1 // At most one of the options
+	CONSTRAINT_ALL_OR_NONE  = 2 // All of the options, or none of them
+	CONSTRAINT_AT_LEAST_ONE = 3 // One or more of the options
+	CONSTRAINT_EXACTLY_ONE  = 4 // Exactly one of the options
+)
+
+// Constraint is a rule over a group of options, evaluated after parsing by
+// [ParseResult.Check]. The options are specified by the long or short
+// names of the non-alias options.
+//
+//	{Kind: argp.CONSTRAINT_EXCLUSIVE, Names: []string{"json", "yaml", "text"}}
+//	{Kind: argp.CONSTRAINT_ALL_OR_NONE, Names: []string{"user", "password"}}
+type Constraint struct {
+	Kind  int      // CONSTRAINT_*
+	Names []string // The option names
+}
+
+// Checks the constraints in order, and returns [Error] for the first
+// violation. The options found in the result are named as supplied, e.g.
+// "--out" or "-o".
+//
+//	conflicting options: --json, -y
+//	options must be used together: --user without --password
+//	one of the options is required: --json, --yaml
+func (p *ParseResult) Check(constraints []Constraint) error {
+	for _, c := range constraints {
+		var found []string // the options as supplied
+		var missing []Option
+		for _, name := range c.Names {
+			if opt := p.GetOpt(name); opt != nil {
+				found = append(found, opt.supplied())
+			} else {
+				missing = append(missing, constraintOption(name))
+			}
+		}
+
+		switch {
+		case len(found) > 1 && (c.Kind == CONSTRAINT_EXCLUSIVE || c.Kind == CONSTRAINT_EXACTLY_ONE):
+			return Error{Message: ErrConflict, Arg: strings.Join(found, ", ")}
+		case len(found) > 0 && len(missing) > 0 && c.Kind == CONSTRAINT_ALL_OR_NONE:
+			arg := fmt.Sprintf("%s without %s", strings.Join(found, ", "), constraintNames(missing))
+			return Error{Message: ErrTogether, Arg: arg, missing: joinLabels(missing)}
+		case len(found) == 0 && (c.Kind == CONSTRAINT_AT_LEAST_ONE || c.Kind == CONSTRAINT_EXACTLY_ONE):
+			return Error{Message: ErrOneOf, Arg: constraintNames(missing), missing: joinLabels(missing)}
+		}
+	}
+	return nil
+}
+
+// Returns the option as supplied, the option name with dashes for the
+// arguments, otherwise the environment variable or the configuration key
+func (r *Result) supplied() string {
+	if r.Source != SOURCE_ARGS {
+		return r.InputString
+	} else if r.short {
+		return "-" + r.InputString
+	} else {
+		return "--" + r.InputString
+	}
+}
+
+// Returns the option of the name, which is a short name if it is a single
+// character
+func constraintOption(name string) Option {
+	if runes := []rune(name); len(runes) == 1 {
+		return Option{Short: runes[0]}
+	}
+	return Option{Long: name}
+}
+
+// Returns the names of the options with dashes, separated by commas
+func constraintNames(options []Option) string {
+	var names []string
+	for _, opt := range options {
+		names = append(names, dashedName(&opt))
+	}
+	return strings.Join(names, ", ")
+}
+
+// Returns the description of the constraint for the help message
+func (c *Constraint) Doc() string {
+	var options []Option
+	for _, name := range c.Names {
+		options = append(options, constraintOption(name))
+	}
+	names := constraintNames(options)
+	switch c.Kind {
+	case CONSTRAINT_EXCLUSIVE:
+		return fmt.Sprintf("Options %s are mutually exclusive.", names)
+	case CONSTRAINT_ALL_OR_NONE:
+		return fmt.Sprintf("Options %s must be used together.", names)
+	case CONSTRAINT_AT_LEAST_ONE:
+		return fmt.Sprintf("At least one of %s is required.", names)
+	case CONSTRAINT_EXACTLY_ONE:
+		return fmt.Sprintf("Exactly one of %s is required.", names)
+	default:
+		return ""
+	}
+}
+
+// Prints the descriptions of the constraints, which can follow the option
+// list in the help message. The lines are wrapped at the right margin of
+// the help format, like [PrintOptList].
+func PrintConstraints(w io.Writer, constraints []Constraint) {
+	PrintConstraintsFormat(w, constraints, HelpFormatEnv())
+}
+
+// Prints the descriptions of the constraints with the help format
+func PrintConstraintsFormat(w io.Writer, constraints []Constraint, f HelpFormat) {
+	for _, c := range constraints {
+		if doc := c.Doc(); !empty_str(doc) {
+			for _, line := range wrapText(doc, f.rmargin()) {
+				fmt.Fprintln(w, line)
+			}
+		}
+	}
+}
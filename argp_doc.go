@@ -0,0 +1,259 @@
+package argp
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// The width of the usage synopsis in the reference documents
+const docWidth = 79
+
+// docSection is a command documented in the reference
+type docSection struct {
+	path  []*Command
+	level int // the heading level, 1 for the root command
+}
+
+// Returns the commands in the tree, depth first
+func docSections(root *Command) []docSection {
+	var sections []docSection
+	var walk func(path []*Command)
+	walk = func(path []*Command) {
+		level := len(path)
+		if level > 5 {
+			level = 5 // leave h6 for the groups
+		}
+		sections = append(sections, docSection{path, level})
+		cmd := path[len(path)-1]
+		for i := range cmd.Commands {
+			walk(append(path[:len(path):len(path)], &cmd.Commands[i]))
+		}
+	}
+	walk([]*Command{root})
+	return sections
+}
+
+// Returns the command of the section
+func (s *docSection) command() *Command {
+	return s.path[len(s.path)-1]
+}
+
+// Returns the anchor of the command, which is the command names joined by '-'
+func (s *docSection) id() string {
+	var names []string
+	for _, cmd := range s.path {
+		if !empty_str(cmd.Name) {
+			names = append(names, cmd.Name)
+		}
+	}
+	return strings.Join(names, "-")
+}
+
+// Returns the usage synopsis of the command
+func (s *docSection) synopsis() string {
+	cmd := s.command()
+	words := synopsisWords(cmd.Options)
+	if len(cmd.Commands) > 0 {
+		words = append(words, "COMMAND")
+	}
+	return wrapWords(commandName(s.path), words, docWidth)
+}
+
+// Returns the anchor of the option in the section, e.g. "prog--output" for
+// the long name, or "prog-o" for the short name.
+func (s *docSection) optionID(opt *Option) string {
+	id := s.id()
+	if !empty_str(opt.Long) {
+		return id + "--" + opt.Long
+	}
+	return id + "-" + string(opt.Short)
+}
+
+// Returns the option names of the row without the aliases, and the names of
+// the aliases.
+func docOptionNames(row *HelpRow) (string, []string) {
+	f := DefaultHelpFormat()
+	f.ShortOptCol = 0
+	f.LongOptCol = 0
+	names := sprintfOptions(row.Option, nil, &f)
+	var aliases []string
+	for _, alias := range row.Aliases {
+		if !empty_rune(alias.Short) {
+			aliases = append(aliases, "-"+string(alias.Short))
+		}
+		if !empty_str(alias.Long) {
+			aliases = append(aliases, "--"+alias.Long)
+		}
+	}
+	return names, aliases
+}
+
+// Returns the heading of the group, "Options" for the first group without a
+// header, or empty for the group following an empty line.
+func docGroupTitle(group *HelpGroup) string {
+	if group.Option == nil {
+		return "Options"
+	}
+	return strings.TrimSuffix(strings.TrimSpace(group.Header), ":")
+}
+
+// Prints the reference of the command tree in Markdown. Each command has a
+// section with the usage synopsis, the options grouped by the headers in the
+// option table, and the links to the subcommands. Each option and command
+// has an anchor made of the command names and the option name, e.g.
+// "prog-build--output". Hidden options are skipped.
+func PrintMarkdown(w io.Writer, root *Command) {
+	for i, s := range docSections(root) {
+		cmd := s.command()
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "<a id=\"%s\"></a>\n\n", s.id())
+		fmt.Fprintf(w, "%s %s\n", strings.Repeat("#", s.level), commandName(s.path))
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(w, "\nAliases: %s\n", markdownCodes(cmd.Aliases))
+		}
+		if !empty_str(cmd.Doc) {
+			fmt.Fprintf(w, "\n%s\n", markdownText(cmd.Doc))
+		}
+		fmt.Fprintf(w, "\n```\n%s```\n", s.synopsis())
+
+		model := newHelpModel(cmd.Options, DefaultHelpFormat())
+		for _, group := range model.Groups {
+			if title := docGroupTitle(&group); !empty_str(title) {
+				fmt.Fprintf(w, "\n%s %s\n", strings.Repeat("#", s.level+1), markdownText(title))
+			}
+			if len(group.Rows) > 0 {
+				fmt.Fprintln(w)
+			}
+			for _, row := range group.Rows {
+				names, aliases := docOptionNames(&row)
+				fmt.Fprintf(w, "- <a id=\"%s\"></a>`%s`", s.optionID(row.Option), names)
+				if doc := optionDoc(row.Option); !empty_str(doc) {
+					fmt.Fprintf(w, ": %s", strings.ReplaceAll(markdownText(doc), "\n", "\n  "))
+				}
+				fmt.Fprintln(w)
+				if len(aliases) > 0 {
+					fmt.Fprintf(w, "  - Aliases: %s\n", markdownCodes(aliases))
+				}
+			}
+		}
+
+		if len(cmd.Commands) > 0 {
+			fmt.Fprintf(w, "\n%s Commands\n\n", strings.Repeat("#", s.level+1))
+			for _, sub := range cmd.Commands {
+				fmt.Fprintf(w, "- [%s](#%s)", sub.Name, docSubID(s.id(), sub.Name))
+				if !empty_str(sub.Doc) {
+					fmt.Fprintf(w, ": %s", markdownText(sub.Doc))
+				}
+				fmt.Fprintln(w)
+			}
+		}
+	}
+}
+
+// Prints the reference of the command tree in a standalone HTML document.
+// The contents and the anchors are the same as [PrintMarkdown].
+func PrintHTML(w io.Writer, root *Command) {
+	esc := html.EscapeString
+	fmt.Fprintln(w, "<!DOCTYPE html>")
+	fmt.Fprintln(w, "<html>")
+	fmt.Fprintln(w, "<head>")
+	fmt.Fprintln(w, "<meta charset=\"utf-8\">")
+	fmt.Fprintf(w, "<title>%s</title>\n", esc(root.Name))
+	fmt.Fprintln(w, "</head>")
+	fmt.Fprintln(w, "<body>")
+	for _, s := range docSections(root) {
+		cmd := s.command()
+		fmt.Fprintf(w, "<section id=\"%s\">\n", esc(s.id()))
+		fmt.Fprintf(w, "<h%d>%s</h%d>\n", s.level, esc(commandName(s.path)), s.level)
+		if len(cmd.Aliases) > 0 {
+			fmt.Fprintf(w, "<p>Aliases: %s</p>\n", htmlCodes(cmd.Aliases))
+		}
+		if !empty_str(cmd.Doc) {
+			fmt.Fprintf(w, "<p>%s</p>\n", htmlText(cmd.Doc))
+		}
+		fmt.Fprintf(w, "<pre>%s</pre>\n", esc(s.synopsis()))
+
+		model := newHelpModel(cmd.Options, DefaultHelpFormat())
+		for _, group := range model.Groups {
+			if title := docGroupTitle(&group); !empty_str(title) {
+				fmt.Fprintf(w, "<h%d>%s</h%d>\n", s.level+1, esc(title), s.level+1)
+			}
+			if len(group.Rows) == 0 {
+				continue
+			}
+			fmt.Fprintln(w, "<dl>")
+			for _, row := range group.Rows {
+				names, aliases := docOptionNames(&row)
+				fmt.Fprintf(w, "<dt id=\"%s\"><code>%s</code></dt>\n", esc(s.optionID(row.Option)), esc(names))
+				fmt.Fprintf(w, "<dd>%s", htmlText(optionDoc(row.Option)))
+				if len(aliases) > 0 {
+					fmt.Fprintf(w, "<br>\nAliases: %s", htmlCodes(aliases))
+				}
+				fmt.Fprintln(w, "</dd>")
+			}
+			fmt.Fprintln(w, "</dl>")
+		}
+
+		if len(cmd.Commands) > 0 {
+			fmt.Fprintf(w, "<h%d>Commands</h%d>\n", s.level+1, s.level+1)
+			fmt.Fprintln(w, "<ul>")
+			for _, sub := range cmd.Commands {
+				fmt.Fprintf(w, "<li><a href=\"#%s\">%s</a>", esc(docSubID(s.id(), sub.Name)), esc(sub.Name))
+				if !empty_str(sub.Doc) {
+					fmt.Fprintf(w, ": %s", htmlText(sub.Doc))
+				}
+				fmt.Fprintln(w, "</li>")
+			}
+			fmt.Fprintln(w, "</ul>")
+		}
+		fmt.Fprintln(w, "</section>")
+	}
+	fmt.Fprintln(w, "</body>")
+	fmt.Fprintln(w, "</html>")
+}
+
+// Returns the anchor of the subcommand
+func docSubID(parent string, name string) string {
+	if empty_str(parent) {
+		return name
+	}
+	return parent + "-" + name
+}
+
+// Returns the names in code spans, separated by commas
+func markdownCodes(names []string) string {
+	list := make([]string, 0, len(names))
+	for _, name := range names {
+		list = append(list, "`"+name+"`")
+	}
+	return strings.Join(list, ", ")
+}
+
+// Returns the text with the Markdown and HTML metacharacters escaped by
+// backslashes
+func markdownText(text string) string {
+	return markdownEscaper.Replace(strings.TrimSpace(text))
+}
+
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "\\<", ">", "\\>", "&", "\\&", "#", "\\#", "|", "\\|",
+)
+
+// Returns the names in code elements, separated by commas
+func htmlCodes(names []string) string {
+	list := make([]string, 0, len(names))
+	for _, name := range names {
+		list = append(list, "<code>"+html.EscapeString(name)+"</code>")
+	}
+	return strings.Join(list, ", ")
+}
+
+// Returns the text escaped for HTML, where the newlines are line breaks
+func htmlText(text string) string {
+	return strings.ReplaceAll(html.EscapeString(strings.TrimSpace(text)), "\n", "<br>\n")
+}
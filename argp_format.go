@@ -0,0 +1,52 @@
+package argp
+
+// FormatArgs returns the string array which reproduces the ParseResult when
+// parsed with the same option table. The options come first, followed by the
+// non-option arguments. "--" is inserted before the arguments if any of them
+// starts with '-'. See [FormatOptions] for the option format.
+func FormatArgs(result ParseResult) []string {
+	args := FormatOptions(result.Options)
+	for _, arg := range result.Args {
+		if len(arg) > 0 && arg[0] == '-' {
+			args = append(args, "--")
+			break
+		}
+	}
+	return append(args, result.Args...)
+}
+
+// FormatOptions returns the string array of the options in the normalized
+// form. The long name is used if available, otherwise the short name. Only
+// the options supplied in the arguments (SOURCE_ARGS) are formatted, so the
+// values from the environment variables and the configuration files, which
+// may be secrets, are not passed on.
+//
+//	--opt ARG, -o ARG     option with an argument
+//	--opt=ARG, -oARG      option with an optional argument
+//	--opt, -o             option without an argument, or the optional
+//	                      argument is omitted
+func FormatOptions(options []Result) []string {
+	var args []string
+	for _, opt := range options {
+		if opt.IsArg() || opt.Source != SOURCE_ARGS {
+			continue
+		}
+		name := "--" + opt.Long
+		sep := "="
+		if empty_str(opt.Long) {
+			name = "-" + string(opt.Short)
+			sep = ""
+		}
+
+		if empty_str(opt.ArgName) {
+			args = append(args, name)
+		} else if opt.Flags&OPTION_ARG_OPTIONAL == 0 {
+			args = append(args, name, opt.Optarg)
+		} else if opt.Optarg == "" {
+			args = append(args, name)
+		} else {
+			args = append(args, name+sep+opt.Optarg)
+		}
+	}
+	return args
+}
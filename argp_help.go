@@ -17,15 +17,41 @@ func empty_str(s string) bool {
 
 // Prints the help message to the [io.Writer]
 func PrintUsage(w io.Writer, options []Option, cmd string, arg string) {
-	fmt.Fprintf(w, "Usage: %s [options...] %s\n", cmd, arg)
-	PrintOptList(w, options)
+	PrintUsageFormat(w, options, cmd, arg, HelpFormatEnv())
+}
+
+// Prints the help message to the [io.Writer] with the help format
+func PrintUsageFormat(w io.Writer, options []Option, cmd string, arg string, f HelpFormat) {
+	defaultHelpTemplate.Execute(w, NewHelpModel(options, cmd, arg, f))
 }
 
 // Prints the help message to the [io.Writer]. This help message only contains
 // the option list.
 func PrintOptList(w io.Writer, options []Option) {
+	PrintOptListFormat(w, options, HelpFormatEnv())
+}
+
+// Prints the option list, where the descriptions are wrapped at the width.
+// If width is 0 or less, the width is taken from the COLUMNS environment
+// variable, or defaults to 79.
+func PrintOptListWidth(w io.Writer, options []Option, width int) {
+	f := HelpFormatEnv()
+	f.RMargin = width
+	PrintOptListFormat(w, options, f)
+}
+
+// Prints the option list with the help format
+func PrintOptListFormat(w io.Writer, options []Option, f HelpFormat) {
+	defaultHelpTemplate.Execute(w, newHelpModel(options, f))
+}
+
+// Returns the help model of the option list, without the usage line
+func newHelpModel(options []Option, f HelpFormat) HelpModel {
+	model := HelpModel{Format: f}
+	group := HelpGroup{}
 	var pOptReal *Option
 	var pOptAliases []*Option
+	var needNote bool
 	for lc := 0; lc < len(options); lc++ {
 
 		opt := options[lc]
@@ -33,6 +59,7 @@ func PrintOptList(w io.Writer, options []Option) {
 		// list the following alias commands, increment the loop counter
 		if opt.Flags&OPTION_ALIAS == 0 {
 			pOptReal = &options[lc]
+			pOptAliases = nil
 
 			for off := 1; lc+off < len(options); off++ {
 				if options[lc+off].Flags&OPTION_ALIAS != 0 {
@@ -47,25 +74,103 @@ func PrintOptList(w io.Writer, options []Option) {
 		}
 
 		if opt.Flags&OPTION_HIDDEN > 0 {
-			// don't print hidden option
+			// don't list hidden option
 			continue
-		} else if empty_rune(opt.Short) && empty_str(opt.Long) && len(opt.Doc) > 0 {
-			// print category header
-			fmt.Fprintln(w, opt.Doc)
+		} else if empty_rune(opt.Short) && empty_str(opt.Long) {
+			// start a new group with the category header, or an empty line
+			if group.Option != nil || len(group.Rows) > 0 {
+				model.Groups = append(model.Groups, group)
+			}
+			group = HelpGroup{Header: opt.Doc, Option: pOptReal}
 		} else {
-			// print options and its decriptions
-			left := sprintfOptions(pOptReal, pOptAliases)
+			// list options and its decriptions
+			left := sprintfOptions(pOptReal, pOptAliases, &f)
+			doc := optionDoc(pOptReal)
+			group.Rows = append(group.Rows, HelpRow{
+				Left:    left,
+				Doc:     doc,
+				Text:    formatRow(left, doc, &f),
+				Option:  pOptReal,
+				Aliases: pOptAliases,
+			})
 
-			docRows := strings.Split(pOptReal.Doc, "\n")
+			needNote = needNote || (!f.DupArgs && hasDupArgs(pOptReal, pOptAliases))
+		}
+	}
+	if group.Option != nil || len(group.Rows) > 0 {
+		model.Groups = append(model.Groups, group)
+	}
 
-			for _, row := range docRows {
-				fmt.Fprintf(w, "%-25s  %s\n", left, row)
-				left = ""
-			}
+	if f.DupArgsNote && needNote {
+		model.Note = strings.Join(wrapText(dupArgsNote, f.rmargin()), "\n")
+	}
+	return model
+}
 
-			pOptAliases = nil
+const dupArgsNote = "Mandatory or optional arguments to long options are also " +
+	"mandatory or optional for any corresponding short options."
+
+// Returns true if the option has both short and long names, and takes an
+// argument. The argument is omitted from the short names unless dup-args.
+func hasDupArgs(optReal *Option, optAlias []*Option) bool {
+	if empty_str(optReal.ArgName) {
+		return false
+	}
+	var short, long bool
+	for _, opt := range append([]*Option{optReal}, optAlias...) {
+		short = short || !empty_rune(opt.Short)
+		long = long || !empty_str(opt.Long)
+	}
+	return short && long
+}
+
+// Prints the option column and the description, see [formatRow]
+func printRow(w io.Writer, left string, doc string, f *HelpFormat) {
+	fmt.Fprint(w, formatRow(left, doc, f))
+}
+
+// Returns the option column and the description. The description is wrapped
+// at the right margin, and starts from the next line if the option column is
+// too long. Each line ends with a newline.
+func formatRow(left string, doc string, f *HelpFormat) string {
+	var buf strings.Builder
+	docWidth := f.rmargin() - f.OptDocCol
+	if docWidth < 20 {
+		docWidth = 20
+	}
+	if cellWidth(left)+2 > f.OptDocCol {
+		buf.WriteString(left + "\n")
+		left = ""
+		if empty_str(doc) {
+			return buf.String()
 		}
 	}
+	for _, row := range strings.Split(doc, "\n") {
+		for _, line := range wrapText(row, docWidth) {
+			buf.WriteString(padRight(left, f.OptDocCol) + line + "\n")
+			left = ""
+		}
+	}
+	return buf.String()
+}
+
+// Returns the description of the option, followed by "[required]" and the
+// environment variable if available.
+func optionDoc(opt *Option) string {
+	var notes []string
+	if opt.Flags&OPTION_REQUIRED > 0 {
+		notes = append(notes, "[required]")
+	}
+	if !empty_str(opt.Env) {
+		notes = append(notes, fmt.Sprintf("[env: %s]", opt.Env))
+	}
+	if len(notes) == 0 {
+		return opt.Doc
+	} else if empty_str(opt.Doc) {
+		return strings.Join(notes, " ")
+	} else {
+		return opt.Doc + " " + strings.Join(notes, " ")
+	}
 }
 
 type argFmt int
@@ -118,7 +223,7 @@ func sprintfLong(long string, arg string, argfmt argFmt) string {
 // The output format depends on how short/long options
 // provided, and their attributes (FLAGS). It tries to mimic the original
 // GNU argp output
-func sprintfOptions(optReal *Option, optAlias []*Option) string {
+func sprintfOptions(optReal *Option, optAlias []*Option, f *HelpFormat) string {
 	var runes []string
 	var longs []string
 	var optList = append([]*Option{optReal}, optAlias...)
@@ -134,14 +239,14 @@ func sprintfOptions(optReal *Option, optAlias []*Option) string {
 	var buf bytes.Buffer
 
 	// indent
-	buf.WriteString(" ")
+	buf.WriteString(spaces(f.ShortOptCol))
 
 	// print short options.
 	if len(runes) > 0 {
 		list := []string{}
 		for _, c := range runes {
 			token := ""
-			if len(longs) > 0 || empty_str(optReal.ArgName) {
+			if (len(longs) > 0 && !f.DupArgs) || empty_str(optReal.ArgName) {
 				// if long option name is defined, skip short option arguments
 				token = sprintfShort(c, "", argFmtNone)
 			} else {
@@ -157,7 +262,7 @@ func sprintfOptions(optReal *Option, optAlias []*Option) string {
 		buf.WriteString(strings.Join(list, ", "))
 	} else {
 		// indent if no short option
-		buf.WriteString("    ")
+		buf.WriteString(spaces(f.LongOptCol - f.ShortOptCol))
 	}
 
 	if len(runes) > 0 && len(longs) > 0 {
@@ -0,0 +1,120 @@
+package argp
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// HelpFormat is the layout of the help message. The parameters are the same
+// as the ARGP_HELP_FMT environment variable of GNU argp. The columns start
+// from 0.
+type HelpFormat struct {
+	ShortOptCol int  // short-opt-col: column of the short options
+	LongOptCol  int  // long-opt-col: column of the long options without short
+	OptDocCol   int  // opt-doc-col: column of the descriptions
+	HeaderCol   int  // header-col: column of the group headers
+	RMargin     int  // rmargin: right margin. 0 uses the terminal width
+	DupArgs     bool // dup-args: print the argument for short options too
+	DupArgsNote bool // dup-args-note: note that the arguments are not duplicated
+}
+
+// Returns the default help format
+func DefaultHelpFormat() HelpFormat {
+	return HelpFormat{
+		ShortOptCol: 1,
+		LongOptCol:  5,
+		OptDocCol:   27,
+		HeaderCol:   0,
+		RMargin:     0,
+		DupArgs:     false,
+		DupArgsNote: false,
+	}
+}
+
+// Returns the default help format, modified by the ARGP_HELP_FMT environment
+// variable. Invalid parameters are ignored.
+func HelpFormatEnv() HelpFormat {
+	f, _ := ParseHelpFormat(os.Getenv("ARGP_HELP_FMT"))
+	return f
+}
+
+// Parses the help format in the ARGP_HELP_FMT syntax, and returns it applied
+// to the default help format. The parameters are separated by comma or
+// whitespace:
+//
+//	short-opt-col=2,long-opt-col=6,opt-doc-col=29,rmargin=79,dup-args
+//
+// Boolean parameters are negated with "no-" prefix. doc-opt-col and
+// usage-indent are accepted for compatibility, but ignored. Returns [Error]
+// for the first invalid parameter, and the format with the other parameters
+// applied.
+func ParseHelpFormat(s string) (HelpFormat, error) {
+	f := DefaultHelpFormat()
+	var firstErr error
+	params := strings.FieldsFunc(s, func(c rune) bool {
+		return c == ',' || c == ' ' || c == '\t' || c == '\n'
+	})
+	for _, param := range params {
+		if err := f.set(param); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return f, firstErr
+}
+
+// applies a "name=value" or a boolean "name" parameter
+func (f *HelpFormat) set(param string) error {
+	name, value, hasValue := strings.Cut(param, "=")
+	invalid := Error{Message: ErrSyntax, Arg: param}
+
+	bools := map[string]*bool{
+		"dup-args":      &f.DupArgs,
+		"dup-args-note": &f.DupArgsNote,
+	}
+	if p, ok := bools[strings.TrimPrefix(name, "no-")]; ok {
+		if hasValue {
+			return invalid
+		}
+		*p = !strings.HasPrefix(name, "no-")
+		return nil
+	}
+
+	ints := map[string]*int{
+		"short-opt-col": &f.ShortOptCol,
+		"long-opt-col":  &f.LongOptCol,
+		"opt-doc-col":   &f.OptDocCol,
+		"header-col":    &f.HeaderCol,
+		"rmargin":       &f.RMargin,
+		"doc-opt-col":   nil,
+		"usage-indent":  nil,
+	}
+	p, ok := ints[name]
+	if !ok || !hasValue {
+		return invalid
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return invalid
+	}
+	if p != nil {
+		*p = n
+	}
+	return nil
+}
+
+// Returns the right margin
+func (f *HelpFormat) rmargin() int {
+	if f.RMargin > 0 {
+		return f.RMargin
+	}
+	return terminalWidth()
+}
+
+// Returns n spaces
+func spaces(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat(" ", n)
+}
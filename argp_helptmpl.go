@@ -0,0 +1,122 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// HelpModel is the data of the help message, which is rendered by a
+// [text/template]. See [DefaultHelpTemplate] for the default layout.
+type HelpModel struct {
+	Usage       string      // The usage line. Empty for the option list only
+	Doc         string      // The text printed after the usage line
+	Arguments   []HelpRow   // The non-option arguments, where Option is nil
+	Groups      []HelpGroup // The option groups
+	Note        string      // The note on the arguments of short options, if any
+	Constraints string      // The descriptions of the constraints, ending with newline
+	PostDoc     string      // The text printed after the option list
+	BugAddress  string      // The address to report bugs
+	Format      HelpFormat  // The help format used to build the model
+}
+
+// HelpGroup is the options following a header in the option table. The first
+// group has no header if the option table does not start with one.
+type HelpGroup struct {
+	Header string    // The header text. Empty for an empty line
+	Option *Option   // The header entry. nil for the first group
+	Rows   []HelpRow // The options in the group. Hidden options are skipped
+}
+
+// HelpRow is an option and its aliases, or a non-option argument, listed in
+// the help message
+type HelpRow struct {
+	Left    string    // The option column, e.g. " -o, --output FILE"
+	Doc     string    // The description, followed by the environment variable
+	Text    string    // The row formatted by the help format, ending with newline
+	Option  *Option   // The option
+	Aliases []*Option // The aliases of the option
+}
+
+// The default help template, which prints the same layout as [PrintUsage],
+// and [Program.PrintHelp] with the documents of the program
+const DefaultHelpTemplate = `{{if .Usage}}{{.Usage}}
+{{end}}
+{{- if .Doc}}{{.Doc}}
+{{end}}
+{{- if or .Doc .Arguments}}
+{{end}}
+{{- if .Arguments}}Arguments:
+{{range .Arguments}}{{.Text}}{{end}}
+{{end}}
+{{- range .Groups}}
+{{- if .Option}}{{indent $.Format.HeaderCol .Header}}
+{{end}}
+{{- range .Rows}}{{.Text}}{{end}}
+{{- end}}
+{{- if .Note}}
+{{.Note}}
+{{end}}
+{{- if .Constraints}}
+{{.Constraints}}
+{{- end}}
+{{- if .PostDoc}}
+{{.PostDoc}}
+{{end}}
+{{- if .BugAddress}}
+Report bugs to {{.BugAddress}}.
+{{end}}`
+
+var defaultHelpTemplate = template.Must(NewHelpTemplate("help", DefaultHelpTemplate))
+
+// Returns the help model of the option table, with the usage line
+func NewHelpModel(options []Option, cmd string, arg string, f HelpFormat) HelpModel {
+	model := newHelpModel(options, f)
+	model.Usage = fmt.Sprintf("Usage: %s [options...] %s", cmd, arg)
+	return model
+}
+
+// Returns the functions available in the help template:
+//
+//	indent N TEXT     indents the non-empty lines by N spaces
+//	pad N TEXT        pads the text with spaces to N cells
+//	wrap N TEXT       wraps the text at N cells
+//
+// The rows are formatted by the help format with [HelpModel.Row], e.g.
+// {{$.Row .Left .Doc}}
+func HelpFuncs() template.FuncMap {
+	return template.FuncMap{
+		"indent": func(n int, text string) string {
+			lines := strings.Split(text, "\n")
+			for i, line := range lines {
+				if !empty_str(line) {
+					lines[i] = spaces(n) + line
+				}
+			}
+			return strings.Join(lines, "\n")
+		},
+		"pad": func(n int, text string) string {
+			return padRight(text, n)
+		},
+		"wrap": func(n int, text string) string {
+			return strings.Join(wrapText(text, n), "\n")
+		},
+	}
+}
+
+// Returns the help template parsed with [HelpFuncs]
+func NewHelpTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(HelpFuncs()).Parse(text)
+}
+
+// Returns the option column and the description formatted by the help
+// format, ending with newline.
+func (m HelpModel) Row(left string, doc string) string {
+	return formatRow(left, doc, &m.Format)
+}
+
+// Prints the help message rendered by the template
+func PrintHelpTemplate(w io.Writer, tmpl *template.Template, model HelpModel) error {
+	return tmpl.Execute(w, model)
+}
@@ -0,0 +1,184 @@
+package argp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prints the manual page in the roff man(7) format. The page contains the
+// sections NAME, SYNOPSIS, DESCRIPTION, ARGUMENTS, OPTIONS, ENVIRONMENT,
+// AUTHORS and BUGS, built from the program and the option table with the
+// standard options. The first line of Doc is the summary in NAME, and the
+// rest is in DESCRIPTION. The options are listed by the same rules as [PrintOptList], and
+// each header in the option table starts a subsection. Empty sections are
+// omitted.
+func (p *Program) PrintMan(options []Option, section int) {
+	fmt.Fprint(p.output(), p.FormatMan(options, section))
+}
+
+// Returns the manual page printed by [Program.PrintMan]
+func (p *Program) FormatMan(options []Option, section int) string {
+	var buf strings.Builder
+	table := p.Options(options)
+	name := p.name()
+
+	fmt.Fprintf(&buf, ".TH \"%s\" \"%d\" \"\" \"%s\" \"User Commands\"\n",
+		roffEscape(strings.ToUpper(name)), section, roffEscape(p.Version))
+
+	buf.WriteString(".SH NAME\n")
+	if summary := p.summary(); !empty_str(summary) {
+		fmt.Fprintf(&buf, "%s \\- %s\n", roffEscape(name), roffEscape(summary))
+	} else {
+		buf.WriteString(roffLine(name))
+	}
+
+	buf.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&buf, ".B %s\n", roffEscape(name))
+	for _, word := range synopsisWords(table) {
+		buf.WriteString(roffLine(word))
+	}
+	if args := p.argsDoc(); !empty_str(args) {
+		buf.WriteString(roffLine(args))
+	}
+
+	if doc := p.description(); !empty_str(doc) || !empty_str(p.PostDoc) {
+		buf.WriteString(".SH DESCRIPTION\n")
+		writeRoffText(&buf, doc, ".PP")
+		if !empty_str(doc) && !empty_str(p.PostDoc) {
+			buf.WriteString(".PP\n")
+		}
+		writeRoffText(&buf, p.PostDoc, ".PP")
+	}
+
+	if len(p.Arguments) > 0 {
+		buf.WriteString(".SH ARGUMENTS\n")
+		for _, arg := range p.Arguments {
+			buf.WriteString(".TP\n")
+			fmt.Fprintf(&buf, "\\fI%s\\fR\n", roffEscape(arg.Usage()))
+			writeRoffText(&buf, arg.Doc, ".IP")
+		}
+	}
+
+	model := newHelpModel(table, DefaultHelpFormat())
+	buf.WriteString(".SH OPTIONS\n")
+	for _, group := range model.Groups {
+		if header := strings.TrimSuffix(strings.TrimSpace(group.Header), ":"); !empty_str(header) {
+			fmt.Fprintf(&buf, ".SS \"%s\"\n", roffEscape(header))
+		}
+		for _, row := range group.Rows {
+			buf.WriteString(".TP\n")
+			buf.WriteString(roffOptions(row.Left) + "\n")
+			writeRoffText(&buf, row.Option.Doc, ".IP")
+		}
+	}
+
+	var env []Option
+	for _, opt := range table {
+		if opt.Flags&(OPTION_HIDDEN|OPTION_ALIAS) == 0 && !empty_str(opt.Env) {
+			env = append(env, opt)
+		}
+	}
+	if len(env) > 0 {
+		buf.WriteString(".SH ENVIRONMENT\n")
+		for _, opt := range env {
+			buf.WriteString(".TP\n")
+			fmt.Fprintf(&buf, ".B %s\n", roffEscape(opt.Env))
+			fmt.Fprintf(&buf, "Sets \\fB%s\\fR.\n", roffEscape(dashedName(&opt)))
+		}
+	}
+
+	if !empty_str(p.Authors) {
+		buf.WriteString(".SH AUTHORS\n")
+		writeRoffText(&buf, p.Authors, ".PP")
+	}
+	if !empty_str(p.BugAddress) {
+		buf.WriteString(".SH BUGS\n")
+		buf.WriteString(roffLine(fmt.Sprintf("Report bugs to %s.", p.BugAddress)))
+	}
+	return buf.String()
+}
+
+// Returns the one-line description of the program, which is the first line
+// of the document without the "name -- " prefix.
+func (p *Program) summary() string {
+	line, _, _ := strings.Cut(strings.TrimSpace(p.Doc), "\n")
+	if _, after, found := strings.Cut(line, " -- "); found {
+		return strings.TrimSpace(after)
+	}
+	return strings.TrimSpace(line)
+}
+
+// Returns the document of the program without the first line, which is the
+// summary
+func (p *Program) description() string {
+	_, rest, _ := strings.Cut(strings.TrimSpace(p.Doc), "\n")
+	return strings.TrimSpace(rest)
+}
+
+// Returns the option name with dashes, the long name if available
+func dashedName(opt *Option) string {
+	if !empty_str(opt.Long) {
+		return "--" + opt.Long
+	}
+	return "-" + string(opt.Short)
+}
+
+// Returns the option column of the help message in roff. The option names
+// are bold, and the argument names are italic.
+//
+//	-o, --output FILE    \fB\-o\fR, \fB\-\-output\fR \fIFILE\fR
+func roffOptions(left string) string {
+	var list []string
+	for _, token := range strings.Split(strings.TrimSpace(left), ", ") {
+		i := strings.IndexAny(token, " [")
+		if i == -1 {
+			list = append(list, fmt.Sprintf("\\fB%s\\fR", roffEscape(token)))
+			continue
+		}
+		name, arg := token[:i], token[i:]
+		var open, end string
+		switch {
+		case strings.HasPrefix(arg, "[="):
+			open, end = "[=", "]"
+		case strings.HasPrefix(arg, "["):
+			open, end = "[", "]"
+		default:
+			open = " "
+		}
+		arg = strings.TrimSuffix(strings.TrimPrefix(arg, open), end)
+		list = append(list, fmt.Sprintf("\\fB%s\\fR%s\\fI%s\\fR%s",
+			roffEscape(name), open, roffEscape(arg), end))
+	}
+	return strings.Join(list, ", ")
+}
+
+// Writes the text as roff paragraphs. Empty lines separate the paragraphs by
+// the macro, e.g. ".PP" or ".IP" in the indented paragraph.
+func writeRoffText(buf *strings.Builder, text string, macro string) {
+	paragraph := false
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if empty_str(line) {
+			paragraph = true
+			continue
+		}
+		if paragraph {
+			buf.WriteString(macro + "\n")
+			paragraph = false
+		}
+		buf.WriteString(roffLine(line))
+	}
+}
+
+// Returns the text line escaped for roff, ending with newline
+func roffLine(line string) string {
+	line = roffEscape(strings.TrimSpace(line))
+	if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+		line = "\\&" + line
+	}
+	return line + "\n"
+}
+
+// Returns the text with the backslashes and hyphens escaped for roff
+func roffEscape(s string) string {
+	return strings.NewReplacer("\\", "\\e", "-", "\\-").Replace(s)
+}
@@ -0,0 +1,171 @@
+package argp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// [Private] Mark this option as injected by the Program
+const _OPTION_PROGRAM = 0x40
+
+// ErrExit is returned by [Program.ParseArgs] after the help message, usage
+// or version is printed. The caller should exit without error.
+var ErrExit = errors.New("argp: help or version printed")
+
+// Program describes the program, and handles the standard options like GNU
+// argp. The standard options -?/--help, --usage and -V/--version are
+// appended to the option table in their own group. The options already
+// defined in the option table are not injected.
+type Program struct {
+	Name       string     // The program name. Defaults to the base name of os.Args[0]
+	Version    string     // The version string. --version is injected if set.
+	BugAddress string     // The address printed at the end of the help message
+	Authors    string     // The authors printed in the man page
+	ArgsDoc    string     // The non-option arguments in the usage, e.g. "FILE..."
+	Arguments  []Argument // The non-option arguments. Used for ArgsDoc if empty
+	Doc        string     // The text printed before the option list
+	PostDoc    string     // The text printed after the option list
+	Output     io.Writer  // The writer to print the messages. Defaults to os.Stdout
+	Completer  Completer  // Completes the non-option arguments. Set nil if unused.
+	Flags      int        // Parse flags (PARSE_*) used for parsing and completion
+
+	Constraints  []Constraint       // Checked after parsing, and noted in the help message
+	HelpTemplate *template.Template // Renders the help message. Defaults to DefaultHelpTemplate
+}
+
+// Returns the option table with the standard options appended
+func (p *Program) Options(options []Option) []Option {
+	var standard []Option
+	add := func(short rune, long string, doc string) {
+		if findLong(options, long) != nil {
+			return
+		}
+		if findShort(options, short) != nil {
+			short = 0
+		}
+		standard = append(standard, Option{Short: short, Long: long, Doc: doc, Flags: _OPTION_PROGRAM})
+	}
+	add('?', "help", "give this help list")
+	add(0, "usage", "give a short usage message")
+	if !empty_str(p.Version) {
+		add('V', "version", "print program version")
+	}
+
+	table := make([]Option, 0, len(options)+len(standard)+1)
+	table = append(table, options...)
+	if len(standard) > 0 {
+		if len(options) > 0 {
+			table = append(table, Option{})
+		}
+		table = append(table, standard...)
+	}
+	return table
+}
+
+// Parse string array with the standard options. If one of the standard
+// options is found, prints the message and returns [ErrExit].
+//
+// If the first argument is [CompleteCommand], prints the candidates for the
+// last argument line by line, and returns [ErrExit]. See [Complete].
+func (p *Program) ParseArgs(options []Option, args []string) (ParseResult, error) {
+	table := p.Options(options)
+	if len(args) > 0 && args[0] == CompleteCommand {
+		for _, candidate := range CompleteFlags(table, args[1:], p.Flags, p.Completer) {
+			fmt.Fprintln(p.output(), candidate)
+		}
+		return ParseResult{}, ErrExit
+	}
+	result, err := ParseArgsFlags(table, args, p.Flags)
+	for _, opt := range result.Options {
+		if opt.Flags&_OPTION_PROGRAM == 0 {
+			continue
+		}
+		switch opt.Long {
+		case "help":
+			p.PrintHelp(table)
+		case "usage":
+			p.PrintUsage(table)
+		case "version":
+			fmt.Fprintln(p.output(), p.Version)
+		}
+		return result, ErrExit
+	}
+	if err == nil && len(p.Arguments) > 0 {
+		err = result.Assign(p.Arguments)
+	}
+	if err == nil {
+		err = result.Check(p.Constraints)
+	}
+	return result, err
+}
+
+// Parse [os.Args] provided
+func (p *Program) Parse(options []Option) (ParseResult, error) {
+	return p.ParseArgs(options, os.Args[1:])
+}
+
+// Prints the help message, which contains the usage, documents, the option
+// list and the bug report address. The message is rendered by HelpTemplate
+// with [Program.HelpModel].
+func (p *Program) PrintHelp(options []Option) {
+	tmpl := p.HelpTemplate
+	if tmpl == nil {
+		tmpl = defaultHelpTemplate
+	}
+	tmpl.Execute(p.output(), p.HelpModel(options))
+}
+
+// Returns the help model of the program. The options are listed as given,
+// so pass the table returned by [Program.Options] to list the standard
+// options.
+func (p *Program) HelpModel(options []Option) HelpModel {
+	f := HelpFormatEnv()
+	model := newHelpModel(options, f)
+	model.Usage = usageLine(p.name(), p.argsDoc())
+	model.Doc = p.Doc
+	model.Arguments = argumentRows(p.Arguments, &f)
+	if len(p.Constraints) > 0 {
+		var buf strings.Builder
+		PrintConstraintsFormat(&buf, p.Constraints, f)
+		model.Constraints = buf.String()
+	}
+	model.PostDoc = p.PostDoc
+	model.BugAddress = p.BugAddress
+	return model
+}
+
+// Prints the usage synopsis, see [PrintSynopsis]
+func (p *Program) PrintUsage(options []Option) {
+	PrintSynopsis(p.output(), options, p.name(), p.argsDoc(), 0)
+}
+
+// Returns ArgsDoc, or the usage of the Arguments if empty
+func (p *Program) argsDoc() string {
+	if empty_str(p.ArgsDoc) {
+		return FormatArgsDoc(p.Arguments)
+	}
+	return p.ArgsDoc
+}
+
+func (p *Program) name() string {
+	if !empty_str(p.Name) {
+		return p.Name
+	}
+	return filepath.Base(os.Args[0])
+}
+
+func (p *Program) output() io.Writer {
+	if p.Output != nil {
+		return p.Output
+	}
+	return os.Stdout
+}
+
+func usageLine(cmd string, arg string) string {
+	return strings.TrimRight(fmt.Sprintf("Usage: %s [options...] %s", cmd, arg), " ")
+}
@@ -0,0 +1,79 @@
+package argp
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ExpandResponseFiles replaces each "@file" argument with the arguments read
+// from the file. The file contents are split by the same rules as
+// [SplitLine]. Response files may contain other "@file" arguments, but
+// cannot include themselves. The relative path in a response file is
+// resolved from the directory of the file. The expansion stops after "--".
+//
+// The error reading the file in the arguments is returned as is. The error
+// reading the nested file is [Error] with ErrResponse, the file and the line
+// of the "@file" argument, and the cause in Err.
+func ExpandResponseFiles(args []string) ([]string, error) {
+	var e expander
+	for _, arg := range args {
+		if err := e.expand(arg, ""); err != nil {
+			return nil, err
+		}
+	}
+	return e.args, nil
+}
+
+// expander holds the state of the response file expansion
+type expander struct {
+	args  []string // expanded arguments
+	stack []string // files being expanded, to detect recursion
+	done  bool     // stop the expansion after "--"
+}
+
+// expands the argument. dir is the directory of the response file
+// containing the argument, or empty for the command line.
+func (e *expander) expand(arg string, dir string) error {
+	if e.done || len(arg) < 2 || arg[0] != '@' {
+		e.done = e.done || arg == "--"
+		e.args = append(e.args, arg)
+		return nil
+	}
+
+	file := arg[1:]
+	if !empty_str(dir) && !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	for _, f := range e.stack {
+		if f == abs {
+			return Error{Message: ErrRecursive, Arg: file}
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	tokens, err := tokenize(string(data))
+	if err != nil {
+		return withPosition(err, file, err.(Error).Line)
+	}
+
+	e.stack = append(e.stack, abs)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+	for _, tok := range tokens {
+		if err := e.expand(tok.text, filepath.Dir(file)); err != nil {
+			if re, ok := err.(Error); !ok {
+				return Error{Message: ErrResponse, Arg: err.Error(), Err: err, File: file, Line: tok.line}
+			} else if empty_str(re.File) {
+				return withPosition(err, file, tok.line)
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package argp
+
+import "strings"
+
+// SplitLine splits the string into an argument array, following the quoting
+// rules of the POSIX shell.
+//
+//	'...'     single quotes preserve all the characters
+//	"..."     double quotes preserve all the characters, except that the
+//	          backslash escapes $ ` " \ and newline
+//	\c        backslash preserves the next character. Backslash followed by
+//	          newline is removed
+//
+// Quoted segments adjacent to other segments form a single argument, e.g.
+// a'b c'"d" is "ab cd". Unlike the shell, no expansion or substitution is
+// performed. Returns [Error] with the line and column of the opening quote
+// if the quote is not closed.
+func SplitLine(line string) ([]string, error) {
+	tokens, err := tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, len(tokens))
+	for _, tok := range tokens {
+		args = append(args, tok.text)
+	}
+	return args, nil
+}
+
+// Parse the string, split by [SplitLine]
+func ParseLine(options []Option, line string) (ParseResult, error) {
+	args, err := SplitLine(line)
+	if err != nil {
+		return ParseResult{}, err
+	}
+	return ParseArgs(options, args)
+}
+
+// token is a word split from the text
+type token struct {
+	text string
+	line int
+}
+
+// splits the text into words by the rules of [SplitLine]
+func tokenize(text string) ([]token, error) {
+	var tokens []token
+	var word []rune
+	var inWord bool
+	var quote rune
+	var escaped bool
+	var quoteLine, quoteColumn int
+	line, column := 1, 0
+
+	for _, c := range text {
+		column++
+		switch {
+		case escaped:
+			escaped = false
+			if c == '\n' {
+				break // line continuation
+			}
+			if quote == '"' && !isDoubleQuoteEscape(c) {
+				word = append(word, '\\')
+			}
+			word = append(word, c)
+			inWord = true
+		case c == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word = append(word, c)
+		case c == '\'' || c == '"':
+			quote = c
+			quoteLine, quoteColumn = line, column
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			if inWord {
+				tokens = append(tokens, token{string(word), line})
+				word = nil
+				inWord = false
+			}
+		default:
+			word = append(word, c)
+			inWord = true
+		}
+		if c == '\n' {
+			line, column = line+1, 0
+		}
+	}
+	if quote != 0 {
+		return nil, Error{Message: ErrQuote, Line: quoteLine, Column: quoteColumn}
+	}
+	if escaped {
+		word = append(word, '\\') // trailing backslash is preserved
+		inWord = true
+	}
+	if inWord {
+		tokens = append(tokens, token{string(word), line})
+	}
+	return tokens, nil
+}
+
+// characters escaped by the backslash in double quotes
+func isDoubleQuoteEscape(c rune) bool {
+	return c == '$' || c == '`' || c == '"' || c == '\\' || c == '\n'
+}
+
+// SplitWindows splits the Windows command line into an argument array, as
+// CommandLineToArgvW does. The first argument is the program name, which
+// ends at the first whitespace or is enclosed in double quotes. Use args[1:]
+// to parse the options. The other arguments follow these rules:
+//
+//	"..."     double quotes preserve whitespace
+//	""        in a quoted argument, a literal double quote and ends quoting
+//	2n \ + "  n backslashes, and the double quote begins or ends quoting
+//	2n+1 \ +" n backslashes and a literal double quote
+//	\         backslashes not followed by a double quote are literal
+//
+// Unterminated quotes are closed at the end of the line.
+func SplitWindows(cmdline string) []string {
+	var args []string
+
+	// the program name
+	cmdline = strings.TrimLeft(cmdline, " \t")
+	if cmdline == "" {
+		return args
+	}
+	if cmdline[0] == '"' {
+		end := strings.IndexByte(cmdline[1:], '"')
+		if end == -1 {
+			return append(args, cmdline[1:])
+		}
+		args = append(args, cmdline[1:end+1])
+		cmdline = cmdline[end+2:]
+	} else {
+		end := strings.IndexAny(cmdline, " \t")
+		if end == -1 {
+			return append(args, cmdline)
+		}
+		args = append(args, cmdline[:end])
+		cmdline = cmdline[end:]
+	}
+
+	for {
+		cmdline = strings.TrimLeft(cmdline, " \t")
+		if cmdline == "" {
+			return args
+		}
+		var arg string
+		arg, cmdline = nextWindowsArg(cmdline)
+		args = append(args, arg)
+	}
+}
+
+// returns the next argument and the rest of the command line
+func nextWindowsArg(cmdline string) (string, string) {
+	var buf strings.Builder
+	var inQuote bool
+	var slashes int
+	for i := 0; i < len(cmdline); i++ {
+		c := cmdline[i]
+		switch {
+		case c == '\\':
+			slashes++
+			continue
+		case c == '"':
+			buf.WriteString(strings.Repeat("\\", slashes/2))
+			if slashes%2 == 1 {
+				buf.WriteByte('"')
+			} else if inQuote && i+1 < len(cmdline) && cmdline[i+1] == '"' {
+				buf.WriteByte('"')
+				inQuote = false
+				i++
+			} else {
+				inQuote = !inQuote
+			}
+			slashes = 0
+			continue
+		case (c == ' ' || c == '\t') && !inQuote:
+			buf.WriteString(strings.Repeat("\\", slashes))
+			return buf.String(), cmdline[i:]
+		}
+		buf.WriteString(strings.Repeat("\\", slashes))
+		buf.WriteByte(c)
+		slashes = 0
+	}
+	buf.WriteString(strings.Repeat("\\", slashes))
+	return buf.String(), ""
+}
+
+// QuoteWindows returns the argument quoted for the Windows command line, so
+// that [SplitWindows] and CommandLineToArgvW restore the original argument.
+func QuoteWindows(arg string) string {
+	if arg == "" {
+		return `""`
+	}
+	if !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	var buf strings.Builder
+	buf.WriteByte('"')
+	slashes := 0
+	for i := 0; i < len(arg); i++ {
+		c := arg[i]
+		switch c {
+		case '\\':
+			slashes++
+		case '"':
+			buf.WriteString(strings.Repeat("\\", slashes*2+1))
+			slashes = 0
+		default:
+			buf.WriteString(strings.Repeat("\\", slashes))
+			slashes = 0
+		}
+		if c != '\\' {
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteString(strings.Repeat("\\", slashes*2))
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// JoinWindows quotes each argument with [QuoteWindows], and joins them with
+// a space. The first argument is the program name, which is enclosed in
+// double quotes if it contains whitespace.
+func JoinWindows(args []string) string {
+	list := make([]string, 0, len(args))
+	for i, arg := range args {
+		if i == 0 && (arg == "" || strings.ContainsAny(arg, " \t")) {
+			list = append(list, `"`+arg+`"`)
+		} else {
+			list = append(list, QuoteWindows(arg))
+		}
+	}
+	return strings.Join(list, " ")
+}
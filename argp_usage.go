@@ -0,0 +1,136 @@
+package argp
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Prints the usage synopsis, which lists all the options like GNU argp:
+//
+//	Usage: cmd [-abc] [-o FILE] [--output=FILE] [--kind[=KIND]] ARGS
+//
+// Short options without argument are grouped into one bracket. Required
+// options are listed without brackets by one of the names, preferably the
+// short name. Hidden options are skipped. Lines are wrapped at the width,
+// and continued with indentation. If width is 0 or less, the width is taken
+// from the COLUMNS environment variable, or defaults to 79.
+func PrintSynopsis(w io.Writer, options []Option, cmd string, arg string, width int) {
+	fmt.Fprint(w, FormatSynopsis(options, cmd, arg, width))
+}
+
+// Returns the usage synopsis printed by [PrintSynopsis]
+func FormatSynopsis(options []Option, cmd string, arg string, width int) string {
+	if width <= 0 {
+		width = terminalWidth()
+	}
+	words := synopsisWords(options)
+	if !empty_str(arg) {
+		words = append(words, arg)
+	}
+	return wrapWords(fmt.Sprintf("Usage: %s", cmd), words, width)
+}
+
+// Returns the options of the usage synopsis
+func synopsisWords(options []Option) []string {
+	var flags []rune
+	var shorts []string
+	var longs []string
+	var required []string
+	var pOptReal *Option
+	var listed bool // the required option is listed
+	for i, opt := range options {
+		if opt.Flags&OPTION_ALIAS == 0 {
+			pOptReal = &options[i]
+			listed = false
+		}
+		if pOptReal == nil || pOptReal.Flags&OPTION_HIDDEN > 0 {
+			continue
+		}
+		if pOptReal.Flags&OPTION_REQUIRED > 0 {
+			if !listed {
+				required = append(required, requiredWord(options[i:], pOptReal))
+				listed = true
+			}
+			continue
+		}
+		if !empty_rune(opt.Short) {
+			if empty_str(pOptReal.ArgName) {
+				flags = append(flags, opt.Short)
+			} else if pOptReal.Flags&OPTION_ARG_OPTIONAL > 0 {
+				shorts = append(shorts, fmt.Sprintf("[-%c[%s]]", opt.Short, pOptReal.ArgName))
+			} else {
+				shorts = append(shorts, fmt.Sprintf("[-%c %s]", opt.Short, pOptReal.ArgName))
+			}
+		}
+		if !empty_str(opt.Long) {
+			if empty_str(pOptReal.ArgName) {
+				longs = append(longs, fmt.Sprintf("[--%s]", opt.Long))
+			} else if pOptReal.Flags&OPTION_ARG_OPTIONAL > 0 {
+				longs = append(longs, fmt.Sprintf("[--%s[=%s]]", opt.Long, pOptReal.ArgName))
+			} else {
+				longs = append(longs, fmt.Sprintf("[--%s=%s]", opt.Long, pOptReal.ArgName))
+			}
+		}
+	}
+
+	var words []string
+	if len(flags) > 0 {
+		words = append(words, fmt.Sprintf("[-%s]", string(flags)))
+	}
+	words = append(words, shorts...)
+	words = append(words, longs...)
+	words = append(words, required...)
+	return words
+}
+
+// Returns the required option in the usage synopsis, e.g. "-o FILE" or
+// "--output=FILE". The short name of the option or the following aliases is
+// preferred.
+func requiredWord(options []Option, optReal *Option) string {
+	short, long := optReal.Short, optReal.Long
+	for i, opt := range options {
+		if i > 0 && opt.Flags&OPTION_ALIAS == 0 {
+			break
+		}
+		if empty_rune(short) {
+			short = opt.Short
+		}
+		if empty_str(long) {
+			long = opt.Long
+		}
+	}
+	arg := optReal.ArgName
+	optional := optReal.Flags&OPTION_ARG_OPTIONAL > 0
+	if !empty_rune(short) {
+		if empty_str(arg) {
+			return fmt.Sprintf("-%c", short)
+		} else if optional {
+			return fmt.Sprintf("-%c[%s]", short, arg)
+		}
+		return fmt.Sprintf("-%c %s", short, arg)
+	}
+	if empty_str(arg) {
+		return fmt.Sprintf("--%s", long)
+	} else if optional {
+		return fmt.Sprintf("--%s[=%s]", long, arg)
+	}
+	return fmt.Sprintf("--%s=%s", long, arg)
+}
+
+// Joins the words after the head with spaces, and wraps the lines at the
+// width. The continued lines are indented to the end of the head.
+func wrapWords(head string, words []string, width int) string {
+	var buf strings.Builder
+	indent := strings.Repeat(" ", cellWidth(head))
+	line := head
+	for _, word := range words {
+		if cellWidth(line)+1+cellWidth(word) > width && line != head && line != indent {
+			buf.WriteString(line + "\n")
+			line = indent
+		}
+		line += " " + word
+	}
+	buf.WriteString(line + "\n")
+	return buf.String()
+}
@@ -0,0 +1,209 @@
+package argp
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Value is the interface to the converted value of an option argument.
+// If an option has a Value, the parser calls Set with the option argument
+// every time the option is found. Options without an argument call Set with
+// "true". Options with an optional argument don't call Set if the argument
+// is omitted.
+//
+// The interface is compatible with [flag.Value].
+type Value interface {
+	Set(string) error
+	String() string
+}
+
+// Returns a Value which stores a string to p
+func String(p *string) Value {
+	return (*stringValue)(p)
+}
+
+// Returns a Value which stores a bool to p
+func Bool(p *bool) Value {
+	return (*boolValue)(p)
+}
+
+// Returns a Value which stores an int to p
+func Int(p *int) Value {
+	return (*intValue)(p)
+}
+
+// Returns a Value which stores an int64 to p
+func Int64(p *int64) Value {
+	return (*int64Value)(p)
+}
+
+// Returns a Value which stores an uint to p
+func Uint(p *uint) Value {
+	return (*uintValue)(p)
+}
+
+// Returns a Value which stores an uint64 to p
+func Uint64(p *uint64) Value {
+	return (*uint64Value)(p)
+}
+
+// Returns a Value which stores a float64 to p
+func Float64(p *float64) Value {
+	return (*float64Value)(p)
+}
+
+// Returns a Value which stores a [time.Duration] to p
+func Duration(p *time.Duration) Value {
+	return (*durationValue)(p)
+}
+
+// Returns a Value which appends the string to p. The option may be
+// supplied multiple times.
+func Strings(p *[]string) Value {
+	return (*stringsValue)(p)
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+func (v *stringValue) String() string {
+	return string(*v)
+}
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	*v = boolValue(b)
+	return nil
+}
+
+func (v *boolValue) String() string {
+	return strconv.FormatBool(bool(*v))
+}
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	i, err := strconv.ParseInt(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*v = intValue(i)
+	return nil
+}
+
+func (v *intValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+type int64Value int64
+
+func (v *int64Value) Set(s string) error {
+	i, err := strconv.ParseInt(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*v = int64Value(i)
+	return nil
+}
+
+func (v *int64Value) String() string {
+	return strconv.FormatInt(int64(*v), 10)
+}
+
+type uintValue uint
+
+func (v *uintValue) Set(s string) error {
+	u, err := strconv.ParseUint(s, 0, strconv.IntSize)
+	if err != nil {
+		return err
+	}
+	*v = uintValue(u)
+	return nil
+}
+
+func (v *uintValue) String() string {
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+type uint64Value uint64
+
+func (v *uint64Value) Set(s string) error {
+	u, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		return err
+	}
+	*v = uint64Value(u)
+	return nil
+}
+
+func (v *uint64Value) String() string {
+	return strconv.FormatUint(uint64(*v), 10)
+}
+
+type float64Value float64
+
+func (v *float64Value) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*v = float64Value(f)
+	return nil
+}
+
+func (v *float64Value) String() string {
+	return strconv.FormatFloat(float64(*v), 'g', -1, 64)
+}
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*v = durationValue(d)
+	return nil
+}
+
+func (v *durationValue) String() string {
+	return time.Duration(*v).String()
+}
+
+type stringsValue []string
+
+func (v *stringsValue) Set(s string) error {
+	*v = append(*v, s)
+	return nil
+}
+
+func (v *stringsValue) String() string {
+	return strings.Join(*v, ",")
+}
+
+// stores the option argument to the Value of the option
+func setValue(opt *Result) error {
+	if opt.Value == nil {
+		return nil
+	}
+	arg := opt.Optarg
+	if empty_str(opt.ArgName) {
+		arg = "true"
+	} else if opt.Flags&OPTION_ARG_OPTIONAL > 0 && arg == "" {
+		return nil
+	}
+	if err := opt.Value.Set(arg); err != nil {
+		return Error{Option: opt.Option, Message: ErrValue, Arg: arg, Err: err}
+	}
+	return nil
+}
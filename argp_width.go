@@ -0,0 +1,207 @@
+package argp
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The right margin used if the terminal width is unknown
+const defaultWidth = 79
+
+// Returns the right margin of the help message. The COLUMNS environment
+// variable is used if available.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns - 1
+	}
+	return defaultWidth
+}
+
+// East Asian Wide and Fullwidth characters, and emoji
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x231A, 0x231B},
+	{0x2329, 0x232A},
+	{0x23E9, 0x23EC},
+	{0x23F0, 0x23F0},
+	{0x23F3, 0x23F3},
+	{0x25FD, 0x25FE},
+	{0x2614, 0x2615},
+	{0x2648, 0x2653},
+	{0x267F, 0x267F},
+	{0x2693, 0x2693},
+	{0x26A1, 0x26A1},
+	{0x26AA, 0x26AB},
+	{0x26BD, 0x26BE},
+	{0x26C4, 0x26C5},
+	{0x26CE, 0x26CE},
+	{0x26D4, 0x26D4},
+	{0x26EA, 0x26EA},
+	{0x26F2, 0x26F3},
+	{0x26F5, 0x26F5},
+	{0x26FA, 0x26FA},
+	{0x26FD, 0x26FD},
+	{0x2705, 0x2705},
+	{0x270A, 0x270B},
+	{0x2728, 0x2728},
+	{0x274C, 0x274C},
+	{0x274E, 0x274E},
+	{0x2753, 0x2755},
+	{0x2757, 0x2757},
+	{0x2795, 0x2797},
+	{0x27B0, 0x27B0},
+	{0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C},
+	{0x2B50, 0x2B50},
+	{0x2B55, 0x2B55},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xA960, 0xA97F},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x16FE0, 0x16FE4},
+	{0x17000, 0x18CFF},
+	{0x1B000, 0x1B2FF},
+	{0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF},
+	{0x1F18E, 0x1F18E},
+	{0x1F191, 0x1F19A},
+	{0x1F200, 0x1F251},
+	{0x1F300, 0x1F320},
+	{0x1F32D, 0x1F335},
+	{0x1F337, 0x1F37C},
+	{0x1F37E, 0x1F393},
+	{0x1F3A0, 0x1F3CA},
+	{0x1F3CF, 0x1F3D3},
+	{0x1F3E0, 0x1F3F0},
+	{0x1F3F4, 0x1F3F4},
+	{0x1F3F8, 0x1F43E},
+	{0x1F440, 0x1F440},
+	{0x1F442, 0x1F4FC},
+	{0x1F4FF, 0x1F53D},
+	{0x1F54B, 0x1F54E},
+	{0x1F550, 0x1F567},
+	{0x1F57A, 0x1F57A},
+	{0x1F595, 0x1F596},
+	{0x1F5A4, 0x1F5A4},
+	{0x1F5FB, 0x1F64F},
+	{0x1F680, 0x1F6C5},
+	{0x1F6CC, 0x1F6CC},
+	{0x1F6D0, 0x1F6D2},
+	{0x1F6D5, 0x1F6D7},
+	{0x1F6EB, 0x1F6EC},
+	{0x1F6F4, 0x1F6FC},
+	{0x1F7E0, 0x1F7EB},
+	{0x1F90C, 0x1F93A},
+	{0x1F93C, 0x1F945},
+	{0x1F947, 0x1F9FF},
+	{0x1FA70, 0x1FAFF},
+	{0x20000, 0x2FFFD},
+	{0x30000, 0x3FFFD},
+}
+
+// Returns the number of cells to display the rune in the terminal
+func runeWidth(r rune) int {
+	if r < 0x20 || r == 0x7F || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	if r < 0x1100 {
+		return 1
+	}
+	lo, hi := 0, len(wideRanges)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if r < wideRanges[mid][0] {
+			hi = mid
+		} else if r > wideRanges[mid][1] {
+			lo = mid + 1
+		} else {
+			return 2
+		}
+	}
+	return 1
+}
+
+// Returns the number of cells to display the string in the terminal
+func cellWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// Returns the string padded with spaces to the width in cells
+func padRight(s string, width int) string {
+	if n := width - cellWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// Splits the text into lines which fit in the width. The text which fits is
+// returned as is. Otherwise the text is broken at the spaces, and each line
+// starts with the leading spaces of the text. A word longer than the width is
+// split at the character.
+func wrapText(text string, width int) []string {
+	if cellWidth(text) <= width {
+		return []string{strings.TrimRight(text, " ")}
+	}
+	body := strings.TrimLeft(text, " ")
+	indent := text[:len(text)-len(body)]
+	if cellWidth(indent) >= width/2 {
+		indent = ""
+	}
+
+	var lines []string
+	var line strings.Builder
+	line.WriteString(indent)
+	lineWidth := cellWidth(indent)
+	empty := true
+	gap := ""
+	for len(body) > 0 {
+		end := strings.IndexByte(body, ' ')
+		if end == -1 {
+			end = len(body)
+		}
+		word := body[:end]
+		body = body[end:]
+		next := strings.TrimLeft(body, " ")
+
+		if !empty && lineWidth+len(gap)+cellWidth(word) > width {
+			lines = append(lines, line.String())
+			line.Reset()
+			line.WriteString(indent)
+			lineWidth = cellWidth(indent)
+			empty = true
+		} else if !empty {
+			line.WriteString(gap)
+			lineWidth += len(gap)
+		}
+		for _, r := range word {
+			rw := runeWidth(r)
+			if !empty && lineWidth+rw > width {
+				lines = append(lines, line.String())
+				line.Reset()
+				line.WriteString(indent)
+				lineWidth = cellWidth(indent)
+			}
+			line.WriteRune(r)
+			lineWidth += rw
+			empty = false
+		}
+		gap = body[:len(body)-len(next)]
+		body = next
+	}
+	lines = append(lines, line.String())
+	return lines
+}
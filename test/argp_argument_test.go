@@ -0,0 +1,127 @@
+package argp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func assign(arguments []argp.Argument, args string) (argp.ParseResult, error) {
+	result, err := argp.ParseArgs(options, split(args))
+	if err != nil {
+		return result, err
+	}
+	return result, result.Assign(arguments)
+}
+
+func Test_Argument(t *testing.T) {
+	var count int
+	arguments := []argp.Argument{
+		{Name: "SRC", Doc: "source"},
+		{Name: "DEST", Doc: "destination", Flags: argp.ARG_OPTIONAL},
+		{Name: "COUNT", Doc: "count", Flags: argp.ARG_OPTIONAL, Value: argp.Int(&count)},
+	}
+
+	result, err := assign(arguments, "a.txt")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.GetArg("SRC"), "a.txt", "")
+	harness.IsEqual(t, result.GetArg("DEST"), "", "")
+	harness.IsEqual(t, len(result.GetArgs("COUNT")), 0, "")
+
+	result, err = assign(arguments, "a.txt -a b.txt 3")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.GetArg("DEST"), "b.txt", "")
+	harness.IsEqual(t, result.GetArg("COUNT"), "3", "")
+	harness.IsEqual(t, count, 3, "")
+
+	_, err = assign(arguments, "-a")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing argument: SRC", "")
+	e := err.(argp.Error)
+	harness.IsEqual(t, e.Message, argp.ErrMissingArg, "")
+	harness.IsEqual(t, e.ArgumentName, "SRC", "")
+
+	count = 0
+	_, err = assign(arguments, "a b 1 c d")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "too many arguments: c", "")
+	e = err.(argp.Error)
+	harness.IsEqual(t, e.Arg, "c", "")
+	harness.IsEqual(t, e.ArgumentName, "", "")
+	harness.IsEqual(t, count, 0, "nothing is set")
+
+	_, err = assign(arguments, "a b x")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument 'x': COUNT", "")
+	e = err.(argp.Error)
+	harness.IsEqual(t, e.Arg, "x", "")
+	harness.IsEqual(t, e.ArgumentName, "COUNT", "")
+}
+
+func Test_ArgumentVariadic(t *testing.T) {
+	// required arguments are filled first
+	arguments := []argp.Argument{
+		{Name: "SRC", Flags: argp.ARG_VARIADIC},
+		{Name: "DEST"},
+	}
+	result, err := assign(arguments, "a b c")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(result.GetArgs("SRC"), ","), "a,b", "")
+	harness.IsEqual(t, result.GetArg("DEST"), "c", "")
+
+	_, err = assign(arguments, "a")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing argument: DEST", "")
+
+	arguments = []argp.Argument{
+		{Name: "CMD"},
+		{Name: "ARGS", Flags: argp.ARG_VARIADIC | argp.ARG_OPTIONAL},
+	}
+	result, err = assign(arguments, "ls")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.GetArgs("ARGS")), 0, "")
+
+	result, err = assign(arguments, "ls -- -l -a")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(result.GetArgs("ARGS"), ","), "-l,-a", "")
+
+	_, err = assign(nil, "a")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "too many arguments: a", "")
+}
+
+func Test_ArgumentHelp(t *testing.T) {
+	arguments := []argp.Argument{
+		{Name: "SRC", Doc: "source files", Flags: argp.ARG_VARIADIC},
+		{Name: "DEST", Doc: "destination"},
+		{Name: "MODE", Doc: "file mode", Flags: argp.ARG_OPTIONAL},
+	}
+	harness.IsEqual(t, argp.FormatArgsDoc(arguments), "SRC... DEST [MODE]", "")
+
+	buf := bytes.NewBufferString("")
+	program := &argp.Program{Name: "prog", Arguments: arguments, Output: buf}
+	options := []argp.Option{{Short: 'v', Long: "verbose", Doc: "verbose output"}}
+
+	_, err := program.ParseArgs(options, split("-v a.txt"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing argument: DEST", "")
+
+	_, err = program.ParseArgs(options, split("--help"))
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	expect := "" +
+		"Usage: prog [options...] SRC... DEST [MODE]\n" +
+		"\n" +
+		"Arguments:\n" +
+		" SRC...                    source files\n" +
+		" DEST                      destination\n" +
+		" [MODE]                    file mode\n" +
+		"\n" +
+		" -v, --verbose             verbose output\n" +
+		"\n" +
+		" -?, --help                give this help list\n" +
+		"     --usage               give a short usage message\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+}
@@ -0,0 +1,83 @@
+package argp_test
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+type bindConfig struct {
+	Output  string        `short:"o" long:"output" argname:"<file>" doc:"output file" default:"a.out"`
+	Verbose []bool        `short:"v" long:"verbose" doc:"verbose level"`
+	Jobs    int           `short:"j" long:"jobs" argname:"<n>" doc:"number of jobs" default:"1"`
+	Ratio   float64       `long:"ratio" argname:"<r>" doc:"ratio"`
+	Timeout time.Duration `long:"timeout" argname:"<d>" doc:"timeout" default:"1s"`
+	Include []string      `short:"I" argname:"<dir>" doc:"include directory"`
+	Level   uint8         `short:"O" argname:"<n>" flags:"optional" doc:"optimize" default:"2"`
+	Debug   bool          `long:"debug" flags:"hidden"`
+	Ignored string
+}
+
+func Test_Bind(t *testing.T) {
+	var cfg bindConfig
+	args := split("-vv -o out.txt --jobs=0x10 -I inc1 -Iinc2 --timeout 3m --ratio 0.5 -O arg0")
+	result, err := argp.Bind(&cfg, args)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, cfg.Output, "out.txt", "")
+	harness.IsEqual(t, len(cfg.Verbose), 2, "")
+	harness.IsEqual(t, cfg.Jobs, 16, "")
+	harness.IsEqual(t, cfg.Ratio, 0.5, "")
+	harness.IsEqual(t, cfg.Timeout, 3*time.Minute, "")
+	harness.IsEqual(t, len(cfg.Include), 2, "")
+	harness.IsEqual(t, cfg.Include[1], "inc2", "")
+	harness.IsEqual(t, cfg.Level, uint8(2), "")
+	harness.IsFalse(t, cfg.Debug, "")
+	harness.IsEqual(t, len(result.Args), 1, "")
+}
+
+func Test_BindDefault(t *testing.T) {
+	var cfg bindConfig
+	_, err := argp.Bind(&cfg, split("--debug -O3"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, cfg.Output, "a.out", "")
+	harness.IsEqual(t, cfg.Jobs, 1, "")
+	harness.IsEqual(t, cfg.Timeout, time.Second, "")
+	harness.IsEqual(t, cfg.Level, uint8(3), "")
+	harness.IsTrue(t, cfg.Debug, "")
+}
+
+func Test_BindNegative(t *testing.T) {
+	var cfg bindConfig
+	_, err := argp.Bind(&cfg, split("--jobs many"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument 'many': --jobs (-j)", "")
+	harness.IsTrue(t, errors.Is(err, strconv.ErrSyntax), "")
+
+	_, err = argp.Bind(cfg, split("--jobs 1"))
+	harness.IsNotNil(t, err, "not a pointer")
+
+	var bad struct {
+		Value complex64 `long:"value"`
+	}
+	_, err = argp.Bind(&bad, split("--value 1"))
+	harness.IsNotNil(t, err, "unsupported type")
+}
+
+func Test_StructOptions(t *testing.T) {
+	options, err := argp.StructOptions(&bindConfig{})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(options), 8, "")
+
+	expect := "" +
+		" -o, --output <file>       output file\n" +
+		" -v, --verbose             verbose level\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptList(buf, options[:2])
+	harness.IsEqual(t, buf.String(), expect, "")
+}
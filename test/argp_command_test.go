@@ -0,0 +1,144 @@
+package argp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var commandTree = argp.Command{
+	Name: "tool",
+	Options: []argp.Option{
+		{Short: 'v', Long: "verbose", Doc: "verbose output"},
+		{Short: 'C', Long: "directory", ArgName: "<dir>", Doc: "change directory"},
+	},
+	Commands: []argp.Command{
+		{
+			Name:    "build",
+			Aliases: []string{"b"},
+			Doc:     "build the project",
+			Options: []argp.Option{
+				{Short: 'j', Long: "jobs", ArgName: "<n>", Doc: "number of jobs"},
+			},
+		},
+		{
+			Name:  "exec",
+			Doc:   "run a command",
+			Flags: argp.PARSE_REQUIRE_ORDER,
+		},
+		{
+			Name: "remote",
+			Doc:  "manage remotes",
+			Commands: []argp.Command{
+				{Name: "add", Doc: "add a remote"},
+			},
+		},
+	},
+}
+
+func Test_Command(t *testing.T) {
+	args := split("-v build -j 4 --directory src arg0")
+	result, err := argp.ParseCommand(&commandTree, args)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Path), 2, "")
+	harness.IsEqual(t, result.Command().Name, "build", "")
+	harness.IsEqual(t, result.Name(), "tool build", "")
+	harness.IsEqual(t, len(result.Results[0].Options), 2, "global options")
+	harness.IsEqual(t, result.Results[0].GetOpt("directory").Optarg, "src", "")
+	harness.IsEqual(t, len(result.Results[1].Options), 1, "build options")
+	harness.IsEqual(t, result.Results[1].GetOpt("j").Optarg, "4", "")
+	harness.IsEqual(t, len(result.Results[0].Args), 0, "")
+	harness.IsEqual(t, len(result.Result().Args), 1, "")
+}
+
+func Test_CommandAlias(t *testing.T) {
+	result, err := argp.ParseCommand(&commandTree, split("b -- -j"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.Command().Name, "build", "")
+	harness.IsEqual(t, len(result.Result().Options), 0, "")
+	harness.IsEqual(t, result.Result().Args[0], "-j", "")
+
+	result, err = argp.ParseCommand(&commandTree, split("remote add origin"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.Name(), "tool remote add", "")
+	harness.IsEqual(t, result.Result().Args[0], "origin", "")
+}
+
+func Test_CommandRequireOrder(t *testing.T) {
+	result, err := argp.ParseCommand(&commandTree, split("exec -v cmd -v -x"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.Command().Name, "exec", "")
+	harness.IsEqual(t, len(result.Results[0].Options), 1, "")
+	harness.IsEqual(t, strings.Join(result.Result().Args, " "), "cmd -v -x", "")
+}
+
+func Test_CommandFlags(t *testing.T) {
+	root := commandTree
+	root.Flags = argp.PARSE_LONG_PREFIX
+
+	// the flags of the root apply to the global options after the subcommand
+	result, err := argp.ParseCommand(&root, split("--verb build --verb --dir src --jo 2"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.Command().Name, "build", "")
+	harness.IsEqual(t, len(result.Results[0].GetOpts("verbose")), 2, "")
+	harness.IsEqual(t, result.Results[0].GetOpt("directory").Optarg, "src", "")
+	harness.IsEqual(t, result.Results[1].GetOpt("jobs").Optarg, "2", "")
+
+	// the flags of the subcommand are combined
+	result, err = argp.ParseCommand(&root, split("exec --verb cmd --verb"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Results[0].Options), 1, "")
+	harness.IsEqual(t, strings.Join(result.Result().Args, " "), "cmd --verb", "")
+}
+
+func Test_CommandNegative(t *testing.T) {
+	_, err := argp.ParseCommand(&commandTree, split("-v unknown"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "unknown command: unknown", "")
+
+	// subcommand options are not accepted before the subcommand name
+	_, err = argp.ParseCommand(&commandTree, split("-j 4 build"))
+	harness.IsNotNil(t, err, "")
+
+	result, err := argp.ParseCommand(&commandTree, []string{})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.Command().Name, "tool", "")
+}
+
+func Test_CommandUsage(t *testing.T) {
+	expect := "" +
+		"Usage: tool [options...] COMMAND\n" +
+		" -v, --verbose             verbose output\n" +
+		" -C, --directory <dir>     change directory\n" +
+		"\n" +
+		"Commands:\n" +
+		" build, b                  build the project\n" +
+		" exec                      run a command\n" +
+		" remote                    manage remotes\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintCommandUsage(buf, []*argp.Command{&commandTree}, "COMMAND")
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_CommandRequired(t *testing.T) {
+	root := argp.Command{
+		Name:    "tool",
+		Options: []argp.Option{{Long: "token", ArgName: "T", Flags: argp.OPTION_REQUIRED}},
+		Commands: []argp.Command{
+			{Name: "push", Options: []argp.Option{{Short: 'r', ArgName: "REMOTE", Flags: argp.OPTION_REQUIRED}}},
+		},
+	}
+
+	_, err := argp.ParseCommand(&root, split("push --token x -r origin"))
+	harness.IsNil(t, err, "")
+
+	// the missing options of all levels are reported
+	result, err := argp.ParseCommand(&root, split("push"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing required option: --token, -r", "")
+	harness.IsEqual(t, result.Name(), "tool push", "")
+}
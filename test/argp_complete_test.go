@@ -0,0 +1,60 @@
+package argp_test
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+var completeOptions = []argp.Option{
+	{Short: 'v', Long: "verbose", Doc: "verbose output"},
+	{Long: "debug", Flags: argp.OPTION_HIDDEN, Doc: "debug output"},
+	{Doc: "Output:"},
+	{Short: 'o', Long: "output", ArgName: "FILE", Doc: "output [file]: it's"},
+	{Short: 'q', Long: "out", Flags: argp.OPTION_ALIAS},
+	{Short: 'K', Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL, Doc: "kind"},
+	{Short: '?', Long: "help"},
+}
+
+// compares the output with the golden file in testdata, or updates the file
+// if -update is given.
+func golden(t *testing.T, name string, print func(w io.Writer)) {
+	t.Helper()
+	buf := bytes.NewBufferString("")
+	print(buf)
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		err := os.WriteFile(path, buf.Bytes(), 0644)
+		harness.IsNil(t, err, "")
+	}
+	expect, err := os.ReadFile(path)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, buf.String(), string(expect), name)
+}
+
+func Test_BashCompletion(t *testing.T) {
+	golden(t, "completion.bash", func(w io.Writer) {
+		argp.PrintBashCompletion(w, completeOptions, "my-prog")
+	})
+}
+
+func Test_ZshCompletion(t *testing.T) {
+	golden(t, "completion.zsh", func(w io.Writer) {
+		argp.PrintZshCompletion(w, completeOptions, "my-prog")
+	})
+}
+
+func Test_FishCompletion(t *testing.T) {
+	golden(t, "completion.fish", func(w io.Writer) {
+		argp.PrintFishCompletion(w, completeOptions, "my-prog")
+	})
+}
@@ -0,0 +1,117 @@
+package argp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+// Value which completes the profile names
+type profileValue struct{ argp.Value }
+
+func (v profileValue) Complete(prefix string) []string {
+	return []string{"default", "dev", "prod"}
+}
+
+func completerOptions() []argp.Option {
+	var profile string
+	return []argp.Option{
+		{Short: 'a', Long: "all", Doc: "all"},
+		{Short: 'b', Long: "brief", Doc: "brief"},
+		{Short: 'o', Long: "branch", ArgName: "BRANCH", Completer: argp.CompleteWords("main", "master", "feature")},
+		{Short: 'B', Long: "br", Flags: argp.OPTION_ALIAS},
+		{Short: 'p', Long: "profile", ArgName: "NAME", Value: profileValue{argp.String(&profile)}},
+		{Short: 'K', Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL, Completer: argp.CompleteWords("x", "y")},
+		{Long: "secret", Flags: argp.OPTION_HIDDEN, ArgName: "S", Completer: argp.CompleteWords("s1")},
+	}
+}
+
+var completerArgs = argp.CompleteFunc(func(prefix string) []string {
+	return []string{"a.txt", "b.txt"}
+})
+
+// returns the candidates joined by ","
+func complete(words string) string {
+	args := strings.Split(words, " ")
+	return strings.Join(argp.Complete(completerOptions(), args, completerArgs), ",")
+}
+
+func Test_Complete(t *testing.T) {
+	// option names
+	harness.IsEqual(t, complete("--b"), "--brief,--branch,--br", "")
+	harness.IsEqual(t, complete("--s"), "", "hidden option is not completed")
+	harness.IsEqual(t, len(strings.Split(complete("-"), ",")), 12, "")
+
+	// option arguments
+	harness.IsEqual(t, complete("-o m"), "main,master", "")
+	harness.IsEqual(t, complete("--branch "), "main,master,feature", "")
+	harness.IsEqual(t, complete("--br=f"), "--br=feature", "")
+	harness.IsEqual(t, complete("-p d"), "default,dev", "Value implements Completer")
+	harness.IsEqual(t, complete("--secret s"), "s1", "")
+	harness.IsEqual(t, complete("--all="), "", "")
+
+	// grouped short options
+	harness.IsEqual(t, complete("-abom"), "-abomain,-abomaster", "")
+	harness.IsEqual(t, complete("-abo"), "-abo", "argument in the next word")
+	harness.IsEqual(t, complete("-ab"), "-ab", "")
+	harness.IsEqual(t, complete("-Kx"), "-Kx", "")
+	harness.IsEqual(t, complete("-ax"), "", "")
+
+	// non-option arguments
+	harness.IsEqual(t, complete("-ab -o main "), "a.txt,b.txt", "")
+	harness.IsEqual(t, complete("a"), "a.txt", "")
+	harness.IsEqual(t, complete("-x --unknown -b "), "a.txt,b.txt", "invalid words are skipped")
+	harness.IsEqual(t, complete("-- --b"), "", "no option after --")
+	harness.IsEqual(t, complete("-- b"), "b.txt", "")
+	harness.IsEqual(t, len(argp.Complete(completerOptions(), nil, nil)), 0, "")
+
+	// optional argument is not taken from the next word
+	harness.IsEqual(t, complete("--kind "), "a.txt,b.txt", "")
+
+	// PARSE_REQUIRE_ORDER stops at the first non-option argument
+	args := strings.Split("file --b", " ")
+	harness.IsEqual(t, len(argp.CompleteFlags(completerOptions(), args, argp.PARSE_REQUIRE_ORDER, nil)), 0, "")
+	harness.IsEqual(t, strings.Join(argp.CompleteFlags(completerOptions(), args, 0, nil), ","), "--brief,--branch,--br", "")
+}
+
+func Test_CompleterCompare(t *testing.T) {
+	option := argp.Option{Long: "branch", ArgName: "BRANCH", Completer: argp.CompleteWords("main")}
+	copied := option
+	harness.IsTrue(t, option == copied, "")
+
+	options := []argp.Option{option}
+	_, err1 := argp.ParseArgs(options, split("--branch"))
+	_, err2 := argp.ParseArgs(options, split("--branch"))
+	harness.IsTrue(t, err1 == err2, "")
+	harness.IsEqual(t, strings.Join(option.Completer.Complete(""), ","), "main", "")
+}
+
+func Test_ProgramComplete(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := newProgram(buf)
+	program.Completer = completerArgs
+
+	_, err := program.ParseArgs(completerOptions(), []string{argp.CompleteCommand, "--he"})
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsEqual(t, buf.String(), "--help\n", "")
+
+	buf.Reset()
+	_, err = program.ParseArgs(completerOptions(), []string{argp.CompleteCommand, "-v", ""})
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsEqual(t, buf.String(), "a.txt\nb.txt\n", "")
+}
+
+func Test_CompletionShim(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		buf := bytes.NewBufferString("")
+		err := argp.PrintCompletionShim(buf, shell, "my-prog")
+		harness.IsNil(t, err, shell)
+		harness.IsTrue(t, strings.Contains(buf.String(), "'my-prog' __complete"), shell)
+	}
+	err := argp.PrintCompletionShim(bytes.NewBufferString(""), "csh", "my-prog")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument: csh", "")
+}
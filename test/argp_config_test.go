@@ -0,0 +1,136 @@
+package argp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var configOptions = []argp.Option{
+	{Short: 'c', Long: "config", ArgName: "<file>", Doc: "configuration file"},
+	{Short: 'o', Long: "output", ArgName: "<file>", Doc: "output file"},
+	{Short: 'q', Long: "quiet", Doc: "quiet mode"},
+	{Short: 'I', Long: "include", ArgName: "<dir>", Doc: "include directory"},
+	{Long: "server-port", ArgName: "<port>", Doc: "server port"},
+	{Long: "port", Flags: argp.OPTION_ALIAS},
+}
+
+func Test_ConfigINI(t *testing.T) {
+	ini := "" +
+		"# comment\n" +
+		"output = \"ini.txt\"\n" +
+		"quiet\n" +
+		"include = a\n" +
+		"include = b\n" +
+		"[server]\n" +
+		"port = 8080\n"
+
+	config, err := argp.ReadConfig(configOptions, strings.NewReader(ini), "app.ini")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(config.Options), 5, "")
+	harness.IsEqual(t, config.GetOpt("output").Optarg, "ini.txt", "")
+	harness.IsEqual(t, config.GetOpt("output").Source, argp.SOURCE_CONFIG, "")
+	harness.IsTrue(t, config.HasOpt("quiet"), "")
+	harness.IsEqual(t, config.GetOpt("server-port").Optarg, "8080", "")
+
+	// command line takes precedence
+	result, err := argp.ParseArgs(configOptions, split("-o cmd.txt arg0"))
+	harness.IsNil(t, err, "")
+	err = result.Merge(config)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.GetOpts("output")), 1, "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "cmd.txt", "")
+	harness.IsEqual(t, len(result.GetOpts("include")), 2, "")
+	harness.IsEqual(t, len(result.Args), 1, "")
+}
+
+func Test_ConfigJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.json")
+	data := "{\n" +
+		"  \"output\": \"json.txt\",\n" +
+		"  \"quiet\": false,\n" +
+		"  \"include\": [\"a\", \"b\"],\n" +
+		"  \"port\": 8080\n" +
+		"}\n"
+	harness.IsNil(t, os.WriteFile(path, []byte(data), 0o644), "")
+
+	config, err := argp.LoadConfig(configOptions, path)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(config.Options), 4, "")
+	harness.IsEqual(t, config.GetOpt("output").Optarg, "json.txt", "")
+	harness.IsFalse(t, config.HasOpt("quiet"), "")
+	harness.IsEqual(t, config.GetOpts("include")[1].Optarg, "b", "")
+	harness.IsEqual(t, config.GetOpt("server-port").Optarg, "8080", "")
+}
+
+func Test_ConfigNegative(t *testing.T) {
+	ini := "output = a.txt\n\nouptut = b.txt\n"
+	_, err := argp.ReadConfig(configOptions, strings.NewReader(ini), "app.ini")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.ini:3: invalid option: --ouptut (did you mean --output?)", "")
+
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("[server\n"), "app.ini")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.ini:1: syntax error: [server", "")
+
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("output\n"), "app.ini")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.ini:1: option requires an argument: --output (-o)", "")
+
+	json := "{\n  \"output\": \"a\",\n  \"unknown\": 1\n}"
+	_, err = argp.ReadConfig(configOptions, strings.NewReader(json), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:3: invalid option: --unknown", "")
+
+	// the errors of the decoder are reported
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("{\n  \"output\": \"a\",\n  \"quiet\" true\n}"), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:3: syntax error: invalid character 't' after object key", "")
+
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("{\n  \"output\": \"a\"\n"), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:3: syntax error: unexpected end of JSON input", "")
+
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("[]"), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:1: syntax error: expected an object", "")
+
+	// objects and nested arrays are not accepted as values
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("{\n  \"output\": {\"a\": 1}\n}"), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:2: syntax error: expected a string, number or boolean: output", "")
+
+	_, err = argp.ReadConfig(configOptions, strings.NewReader("{\n  \"include\": [\"a\", [\"b\"]]\n}"), "app.json")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "app.json:2: syntax error: expected a string, number or boolean: include", "")
+
+	_, err = argp.LoadConfig(configOptions, filepath.Join(t.TempDir(), "missing.ini"))
+	harness.IsTrue(t, os.IsNotExist(err), "")
+}
+
+func Test_ConfigRequired(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'o', Long: "output", ArgName: "<file>", Flags: argp.OPTION_REQUIRED, Doc: "output file"},
+		{Short: 'q', Long: "quiet", Doc: "quiet mode"},
+	}
+
+	_, err := argp.ParseArgs(options, split("-q"))
+	harness.IsNotNil(t, err, "")
+
+	// the configuration file supplies the required option
+	result, err := argp.ParseArgsFlags(options, split("-q"), argp.PARSE_DEFER_REQUIRED)
+	harness.IsNil(t, err, "")
+	err = result.CheckRequired(options)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing required option: --output (-o)", "")
+
+	config, err := argp.ReadConfig(options, strings.NewReader("output = a.txt\n"), "app.ini")
+	harness.IsNil(t, err, "")
+	harness.IsNil(t, result.Merge(config), "")
+	harness.IsNil(t, result.CheckRequired(options), "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "a.txt", "")
+}
@@ -0,0 +1,142 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var constraintOptions = []argp.Option{
+	{Short: 'j', Long: "json", Doc: "json output"},
+	{Short: 'y', Long: "yaml", Doc: "yaml output"},
+	{Long: "text", Doc: "text output"},
+	{Short: 'u', Long: "user", ArgName: "USER"},
+	{Long: "password", ArgName: "PASS", Env: "TEST_CONSTRAINT_PASSWORD"},
+	{Long: "host", ArgName: "HOST"},
+}
+
+var constraints = []argp.Constraint{
+	{Kind: argp.CONSTRAINT_EXCLUSIVE, Names: []string{"json", "yaml", "text"}},
+	{Kind: argp.CONSTRAINT_ALL_OR_NONE, Names: []string{"user", "password", "host"}},
+}
+
+func check(args string, constraints []argp.Constraint) error {
+	result, err := argp.ParseArgsFlags(constraintOptions, split(args), argp.PARSE_LONG_PREFIX)
+	if err != nil {
+		return err
+	}
+	return result.Check(constraints)
+}
+
+func Test_Constraint(t *testing.T) {
+	harness.IsNil(t, check("--json", constraints), "")
+	harness.IsNil(t, check("--user a --password b --host c", constraints), "")
+
+	err := check("--js -y", constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: --js, -y", "named as supplied")
+
+	err = check("-u a --text --json", constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: --json, --text", "")
+
+	err = check("--host c -u a", constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "options must be used together: -u, --host without --password", "")
+	harness.IsEqual(t, len(err.(argp.Error).Missing()), 1, "")
+	harness.IsEqual(t, err.(argp.Error).Missing()[0], "--password", "")
+
+	err = check("-u a", constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "options must be used together: -u without --password, --host", "")
+
+	// the environment variable is named by itself
+	t.Setenv("TEST_CONSTRAINT_PASSWORD", "b")
+	err = check("", constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "options must be used together: TEST_CONSTRAINT_PASSWORD without --user, --host", "")
+}
+
+func Test_ConstraintOneOf(t *testing.T) {
+	atLeastOne := []argp.Constraint{{Kind: argp.CONSTRAINT_AT_LEAST_ONE, Names: []string{"json", "y"}}}
+	exactlyOne := []argp.Constraint{{Kind: argp.CONSTRAINT_EXACTLY_ONE, Names: []string{"json", "y"}}}
+
+	harness.IsNil(t, check("-j", atLeastOne), "")
+	harness.IsNil(t, check("-j --yaml", atLeastOne), "")
+	harness.IsNil(t, check("--yaml", exactlyOne), "")
+
+	err := check("--text", atLeastOne)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "one of the options is required: --json, -y", "")
+
+	err = check("", exactlyOne)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "one of the options is required: --json, -y", "")
+
+	err = check("-jy", exactlyOne)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: -j, -y", "")
+}
+
+func Test_ConstraintDoc(t *testing.T) {
+	t.Setenv("COLUMNS", "40")
+	buf := bytes.NewBufferString("")
+	argp.PrintConstraints(buf, append(constraints,
+		argp.Constraint{Kind: argp.CONSTRAINT_AT_LEAST_ONE, Names: []string{"json", "y"}},
+		argp.Constraint{Kind: argp.CONSTRAINT_EXACTLY_ONE, Names: []string{"user", "host"}},
+	))
+	expect := "" +
+		"Options --json, --yaml, --text are\n" +
+		"mutually exclusive.\n" +
+		"Options --user, --password, --host must\n" +
+		"be used together.\n" +
+		"At least one of --json, -y is required.\n" +
+		"Exactly one of --user, --host is\n" +
+		"required.\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	// the right margin of the help format
+	t.Setenv("COLUMNS", "")
+	t.Setenv("ARGP_HELP_FMT", "rmargin=30")
+	buf.Reset()
+	argp.PrintConstraints(buf, constraints[:1])
+	harness.IsEqual(t, buf.String(), "Options --json, --yaml, --text\nare mutually exclusive.\n", "")
+}
+
+func Test_ProgramConstraint(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := &argp.Program{Name: "prog", Output: buf, Constraints: constraints[:1]}
+
+	_, err := program.ParseArgs(constraintOptions, split("-j -y"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: -j, -y", "")
+
+	_, err = program.ParseArgs(constraintOptions, split("-j -y --help"))
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsTrue(t, bytes.Contains(buf.Bytes(), []byte("\nOptions --json, --yaml, --text are mutually exclusive.\n")), "")
+}
+
+func Test_ConstraintAlias(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'j', Long: "json", Doc: "json output"},
+		{Short: 'J', Flags: argp.OPTION_ALIAS},
+		{Long: "yaml", Doc: "yaml output"},
+		{Short: 'y', Long: "yml", Flags: argp.OPTION_ALIAS},
+	}
+	constraints := []argp.Constraint{{Kind: argp.CONSTRAINT_EXCLUSIVE, Names: []string{"json", "yaml"}}}
+
+	// the aliases are named as supplied
+	result, err := argp.ParseArgs(options, split("-J -y"))
+	harness.IsNil(t, err, "")
+	err = result.Check(constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: -J, -y", "")
+
+	result, err = argp.ParseArgs(options, split("--json --yml"))
+	harness.IsNil(t, err, "")
+	err = result.Check(constraints)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "conflicting options: --json, --yml", "")
+}
@@ -0,0 +1,139 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var docCommand = &argp.Command{
+	Name: "prog",
+	Doc:  "prog -- a test program",
+	Options: []argp.Option{
+		{Short: 'v', Long: "verbose", Doc: "verbose output"},
+		{Long: "debug", Flags: argp.OPTION_HIDDEN},
+		{Doc: "Output:"},
+		{Short: 'o', Long: "output", ArgName: "FILE", Doc: "output <file>\nsecond line", Env: "OUT"},
+		{Short: 'q', Long: "out", Flags: argp.OPTION_ALIAS},
+		{Doc: ""},
+		{Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL},
+	},
+	Commands: []argp.Command{
+		{Name: "build", Aliases: []string{"b"}, Doc: "build it", Options: []argp.Option{
+			{Short: 'j', Long: "jobs", ArgName: "N", Doc: "jobs"},
+		}},
+	},
+}
+
+func Test_Markdown(t *testing.T) {
+	expect := "" +
+		"<a id=\"prog\"></a>\n" +
+		"\n" +
+		"# prog\n" +
+		"\n" +
+		"prog -- a test program\n" +
+		"\n" +
+		"```\n" +
+		"prog [-v] [-o FILE] [-q FILE] [--verbose] [--output=FILE] [--out=FILE]\n" +
+		"     [--kind[=KIND]] COMMAND\n" +
+		"```\n" +
+		"\n" +
+		"## Options\n" +
+		"\n" +
+		"- <a id=\"prog--verbose\"></a>`-v, --verbose`: verbose output\n" +
+		"\n" +
+		"## Output\n" +
+		"\n" +
+		"- <a id=\"prog--output\"></a>`-o, --output FILE`: output \\<file\\>\n" +
+		"  second line \\[env: OUT\\]\n" +
+		"  - Aliases: `-q`, `--out`\n" +
+		"\n" +
+		"- <a id=\"prog--kind\"></a>`--kind[=KIND]`\n" +
+		"\n" +
+		"## Commands\n" +
+		"\n" +
+		"- [build](#prog-build): build it\n" +
+		"\n" +
+		"<a id=\"prog-build\"></a>\n" +
+		"\n" +
+		"## prog build\n" +
+		"\n" +
+		"Aliases: `b`\n" +
+		"\n" +
+		"build it\n" +
+		"\n" +
+		"```\n" +
+		"prog build [-j N] [--jobs=N]\n" +
+		"```\n" +
+		"\n" +
+		"### Options\n" +
+		"\n" +
+		"- <a id=\"prog-build--jobs\"></a>`-j, --jobs N`: jobs\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintMarkdown(buf, docCommand)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_HTML(t *testing.T) {
+	expect := "" +
+		"<!DOCTYPE html>\n" +
+		"<html>\n" +
+		"<head>\n" +
+		"<meta charset=\"utf-8\">\n" +
+		"<title>prog</title>\n" +
+		"</head>\n" +
+		"<body>\n" +
+		"<section id=\"prog\">\n" +
+		"<h1>prog</h1>\n" +
+		"<p>prog -- a test program</p>\n" +
+		"<pre>prog [-v] [-o FILE] [-q FILE] [--verbose] [--output=FILE] [--out=FILE]\n" +
+		"     [--kind[=KIND]] COMMAND\n" +
+		"</pre>\n" +
+		"<h2>Options</h2>\n" +
+		"<dl>\n" +
+		"<dt id=\"prog--verbose\"><code>-v, --verbose</code></dt>\n" +
+		"<dd>verbose output</dd>\n" +
+		"</dl>\n" +
+		"<h2>Output</h2>\n" +
+		"<dl>\n" +
+		"<dt id=\"prog--output\"><code>-o, --output FILE</code></dt>\n" +
+		"<dd>output &lt;file&gt;<br>\n" +
+		"second line [env: OUT]<br>\n" +
+		"Aliases: <code>-q</code>, <code>--out</code></dd>\n" +
+		"</dl>\n" +
+		"<dl>\n" +
+		"<dt id=\"prog--kind\"><code>--kind[=KIND]</code></dt>\n" +
+		"<dd></dd>\n" +
+		"</dl>\n" +
+		"<h2>Commands</h2>\n" +
+		"<ul>\n" +
+		"<li><a href=\"#prog-build\">build</a>: build it</li>\n" +
+		"</ul>\n" +
+		"</section>\n" +
+		"<section id=\"prog-build\">\n" +
+		"<h2>prog build</h2>\n" +
+		"<p>Aliases: <code>b</code></p>\n" +
+		"<p>build it</p>\n" +
+		"<pre>prog build [-j N] [--jobs=N]\n" +
+		"</pre>\n" +
+		"<h3>Options</h3>\n" +
+		"<dl>\n" +
+		"<dt id=\"prog-build--jobs\"><code>-j, --jobs N</code></dt>\n" +
+		"<dd>jobs</dd>\n" +
+		"</dl>\n" +
+		"</section>\n" +
+		"</body>\n" +
+		"</html>\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintHTML(buf, docCommand)
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	// the output is deterministic
+	again := bytes.NewBufferString("")
+	argp.PrintHTML(again, docCommand)
+	harness.IsEqual(t, again.String(), buf.String(), "")
+}
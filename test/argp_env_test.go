@@ -0,0 +1,69 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var envOptions = []argp.Option{
+	{Short: 'o', Long: "output", ArgName: "<file>", Doc: "output file", Env: "APP_OUTPUT"},
+	{Short: 'q', Long: "quiet", Doc: "quiet mode", Env: "APP_QUIET"},
+	{Long: "dry-run", Doc: "dry run"},
+}
+
+func Test_Env(t *testing.T) {
+	t.Setenv("APP_OUTPUT", "env.txt")
+	t.Setenv("APP_QUIET", "true")
+
+	result, err := argp.ParseArgs(envOptions, split("arg0"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 2, "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "env.txt", "")
+	harness.IsEqual(t, result.GetOpt("output").Source, argp.SOURCE_ENV, "")
+	harness.IsEqual(t, result.GetOpt("output").InputString, "APP_OUTPUT", "")
+	harness.IsTrue(t, result.HasOpt("quiet"), "")
+	harness.IsEqual(t, len(result.Ordered), 1, "")
+
+	// command line takes precedence
+	result, err = argp.ParseArgs(envOptions, split("-o cmd.txt"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.GetOpts("output")), 1, "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "cmd.txt", "")
+	harness.IsEqual(t, result.GetOpt("output").Source, argp.SOURCE_ARGS, "")
+
+	t.Setenv("APP_QUIET", "0")
+	result, err = argp.ParseArgs(envOptions, []string{})
+	harness.IsNil(t, err, "")
+	harness.IsFalse(t, result.HasOpt("quiet"), "")
+
+	t.Setenv("APP_QUIET", "maybe")
+	_, err = argp.ParseArgs(envOptions, []string{})
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument 'maybe': --quiet (-q)", "")
+}
+
+func Test_EnvPrefix(t *testing.T) {
+	t.Setenv("APP_DRY_RUN", "1")
+	options := argp.EnvPrefix(envOptions, "APP_")
+	harness.IsEqual(t, options[0].Env, "APP_OUTPUT", "")
+	harness.IsEqual(t, options[2].Env, "APP_DRY_RUN", "")
+	harness.IsEqual(t, envOptions[2].Env, "", "original table is unchanged")
+
+	result, err := argp.ParseArgs(options, []string{})
+	harness.IsNil(t, err, "")
+	harness.IsTrue(t, result.HasOpt("dry-run"), "")
+}
+
+func Test_EnvHelp(t *testing.T) {
+	expect := "" +
+		" -o, --output <file>       output file [env: APP_OUTPUT]\n" +
+		" -q, --quiet               quiet mode [env: APP_QUIET]\n" +
+		"     --dry-run             dry run\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptList(buf, envOptions)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
@@ -0,0 +1,58 @@
+package argp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func Test_FormatArgs(t *testing.T) {
+	args := split("arg0 -ab -o- -Kx --kind -1 -e5 -x -- -f f.txt -- -z")
+	result, err := argp.ParseArgs(options, args)
+	harness.IsNil(t, err, "")
+
+	formatted := argp.FormatArgs(result)
+	expect := "--aaa --bbb --output - --kind=x --kind -1 --eee=5 --xxxx -- --file f.txt -- arg0 -z"
+	harness.IsEqual(t, strings.Join(formatted, " "), expect, "")
+
+	reparsed, err := argp.ParseArgs(options, formatted)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(reparsed.Options), len(result.Options), "")
+	for i := range result.Options {
+		harness.IsEqual(t, reparsed.Options[i].Name(), result.Options[i].Name(), "")
+		harness.IsEqual(t, reparsed.Options[i].Optarg, result.Options[i].Optarg, "")
+	}
+	harness.IsEqual(t, strings.Join(reparsed.Args, " "), strings.Join(result.Args, " "), "")
+}
+
+func Test_FormatOptions(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'o', ArgName: "<file>"},
+		{Short: 'O', ArgName: "<n>", Flags: argp.OPTION_ARG_OPTIONAL},
+		{Short: 'v'},
+	}
+	result, err := argp.ParseArgs(options, split("-vo out -O -O2 arg0"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(argp.FormatOptions(result.Options), " "), "-v -o out -O -O2", "")
+	harness.IsEqual(t, strings.Join(argp.FormatArgs(result), " "), "-v -o out -O -O2 arg0", "")
+}
+
+func Test_FormatOptionsSource(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'v', Long: "verbose"},
+		{Long: "token", ArgName: "<token>", Env: "TEST_FORMAT_TOKEN"},
+		{Long: "output", ArgName: "<file>"},
+	}
+	t.Setenv("TEST_FORMAT_TOKEN", "secret")
+	result, err := argp.ParseArgs(options, split("-v arg0"))
+	harness.IsNil(t, err, "")
+	config, err := argp.ReadConfig(options, strings.NewReader("output = a.txt\n"), "app.ini")
+	harness.IsNil(t, err, "")
+	harness.IsNil(t, result.Merge(config), "")
+	harness.IsEqual(t, len(result.Options), 3, "")
+
+	// the environment variable and the configuration file are not formatted
+	harness.IsEqual(t, strings.Join(argp.FormatArgs(result), " "), "--verbose arg0", "")
+}
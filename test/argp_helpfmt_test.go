@@ -0,0 +1,87 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var helpFmtOptions = []argp.Option{
+	{Doc: "OPTIONS:"},
+	{Short: 'o', Long: "output", ArgName: "FILE", Doc: "output file"},
+	{Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL, Doc: "kind"},
+	{Short: 'v', Doc: "verbose"},
+}
+
+func Test_ParseHelpFormat(t *testing.T) {
+	f, err := argp.ParseHelpFormat("short-opt-col=2, long-opt-col=6,opt-doc-col=29 rmargin=60,dup-args,no-dup-args-note,usage-indent=12")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, f.ShortOptCol, 2, "")
+	harness.IsEqual(t, f.LongOptCol, 6, "")
+	harness.IsEqual(t, f.OptDocCol, 29, "")
+	harness.IsEqual(t, f.HeaderCol, 0, "")
+	harness.IsEqual(t, f.RMargin, 60, "")
+	harness.IsTrue(t, f.DupArgs, "")
+	harness.IsFalse(t, f.DupArgsNote, "")
+
+	f, err = argp.ParseHelpFormat("rmargin=abc,header-col=2,dup-args=1,unknown=1")
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "syntax error: rmargin=abc", "")
+	harness.IsEqual(t, f.HeaderCol, 2, "valid parameters are applied")
+	harness.IsEqual(t, f.RMargin, 0, "")
+
+	f, err = argp.ParseHelpFormat("")
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, f, argp.DefaultHelpFormat(), "")
+}
+
+func Test_HelpFormat(t *testing.T) {
+	f := argp.DefaultHelpFormat()
+	f.ShortOptCol = 2
+	f.LongOptCol = 6
+	f.OptDocCol = 29
+	f.HeaderCol = 1
+	f.DupArgs = true
+
+	expect := "" +
+		" OPTIONS:\n" +
+		"  -o FILE, --output FILE     output file\n" +
+		"      --kind[=KIND]          kind\n" +
+		"  -v                         verbose\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptListFormat(buf, helpFmtOptions, f)
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	f = argp.DefaultHelpFormat()
+	f.RMargin = 60
+	f.DupArgsNote = true
+	expect = "" +
+		"OPTIONS:\n" +
+		" -o, --output FILE         output file\n" +
+		"     --kind[=KIND]         kind\n" +
+		" -v                        verbose\n" +
+		"\n" +
+		"Mandatory or optional arguments to long options are also\n" +
+		"mandatory or optional for any corresponding short options.\n"
+
+	buf.Reset()
+	argp.PrintOptListFormat(buf, helpFmtOptions, f)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_HelpFormatEnv(t *testing.T) {
+	t.Setenv("ARGP_HELP_FMT", "opt-doc-col=20,dup-args")
+	expect := "" +
+		"OPTIONS:\n" +
+		" -o FILE, --output FILE\n" +
+		"                    output file\n" +
+		"     --kind[=KIND]  kind\n" +
+		" -v                 verbose\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptList(buf, helpFmtOptions)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
@@ -0,0 +1,119 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var helpTmplOptions = []argp.Option{
+	{Short: 'v', Long: "verbose", Doc: "verbose output"},
+	{Long: "debug", Flags: argp.OPTION_HIDDEN, Doc: "debug output"},
+	{Doc: "Output:"},
+	{Short: 'o', Long: "output", ArgName: "FILE", Doc: "output file", Env: "OUTPUT"},
+	{Long: "out", Flags: argp.OPTION_ALIAS},
+}
+
+func Test_HelpModel(t *testing.T) {
+	model := argp.NewHelpModel(helpTmplOptions, "prog", "FILE", argp.DefaultHelpFormat())
+	harness.IsEqual(t, model.Usage, "Usage: prog [options...] FILE", "")
+	harness.IsEqual(t, len(model.Groups), 2, "")
+
+	harness.IsEqual(t, model.Groups[0].Header, "", "")
+	harness.IsTrue(t, model.Groups[0].Option == nil, "")
+	harness.IsEqual(t, len(model.Groups[0].Rows), 1, "hidden option is skipped")
+	harness.IsEqual(t, model.Groups[0].Rows[0].Left, " -v, --verbose", "")
+
+	row := model.Groups[1].Rows[0]
+	harness.IsEqual(t, model.Groups[1].Header, "Output:", "")
+	harness.IsEqual(t, row.Left, " -o, --output FILE, --out FILE", "")
+	harness.IsEqual(t, row.Doc, "output file [env: OUTPUT]", "")
+	harness.IsEqual(t, row.Text, " -o, --output FILE, --out FILE\n                           output file [env: OUTPUT]\n", "")
+	harness.IsEqual(t, row.Option.Long, "output", "")
+	harness.IsEqual(t, len(row.Aliases), 1, "")
+}
+
+func Test_HelpTemplate(t *testing.T) {
+	f := argp.DefaultHelpFormat()
+	f.RMargin = 79
+
+	// the default template prints the same message as PrintUsageFormat
+	expect := bytes.NewBufferString("")
+	argp.PrintUsageFormat(expect, helpTmplOptions, "prog", "FILE", f)
+
+	tmpl, err := argp.NewHelpTemplate("help", argp.DefaultHelpTemplate)
+	harness.IsNil(t, err, "")
+	buf := bytes.NewBufferString("")
+	err = argp.PrintHelpTemplate(buf, tmpl, argp.NewHelpModel(helpTmplOptions, "prog", "FILE", f))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, buf.String(), expect.String(), "")
+
+	// a custom template
+	tmpl, err = argp.NewHelpTemplate("help", `== {{.Usage}} ==
+{{range .Groups}}{{if .Header}}[{{.Header}}]
+{{end}}{{range .Rows}}{{$.Row (pad 20 .Left) (print "* " .Doc)}}{{end}}{{end}}
+Examples:
+{{indent 2 "prog -v a.txt\nprog -o out.txt a.txt"}}
+{{wrap 20 "See https://example.com/docs for the documentation."}}
+`)
+	harness.IsNil(t, err, "")
+
+	expectCustom := "" +
+		"== Usage: prog [options...] FILE ==\n" +
+		" -v, --verbose             * verbose output\n" +
+		"[Output:]\n" +
+		" -o, --output FILE, --out FILE\n" +
+		"                           * output file [env: OUTPUT]\n" +
+		"\n" +
+		"Examples:\n" +
+		"  prog -v a.txt\n" +
+		"  prog -o out.txt a.txt\n" +
+		"See\n" +
+		"https://example.com/\n" +
+		"docs for the\n" +
+		"documentation.\n"
+
+	buf.Reset()
+	err = argp.PrintHelpTemplate(buf, tmpl, argp.NewHelpModel(helpTmplOptions, "prog", "FILE", f))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, buf.String(), expectCustom, "")
+}
+
+func Test_ProgramHelpTemplate(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := &argp.Program{
+		Name:       "prog",
+		Doc:        "prog -- a test program",
+		PostDoc:    "See the manual.",
+		BugAddress: "<bugs@example.com>",
+		Arguments:  []argp.Argument{{Name: "FILE", Doc: "input file"}},
+		Output:     buf,
+	}
+
+	model := program.HelpModel(helpTmplOptions)
+	harness.IsEqual(t, model.Usage, "Usage: prog [options...] FILE", "")
+	harness.IsEqual(t, model.Doc, "prog -- a test program", "")
+	harness.IsEqual(t, len(model.Arguments), 1, "")
+	harness.IsEqual(t, model.Arguments[0].Left, " FILE", "")
+	harness.IsEqual(t, model.BugAddress, "<bugs@example.com>", "")
+
+	program.HelpTemplate = template.Must(argp.NewHelpTemplate("help", `{{.Doc}}
+{{.Usage}}
+{{range .Arguments}}{{.Text}}{{end}}
+{{- range .Groups}}{{range .Rows}}{{.Text}}{{end}}{{end}}
+{{- .PostDoc}} Bugs: {{.BugAddress}}
+`))
+	program.PrintHelp(helpTmplOptions)
+	expect := "" +
+		"prog -- a test program\n" +
+		"Usage: prog [options...] FILE\n" +
+		" FILE                      input file\n" +
+		" -v, --verbose             verbose output\n" +
+		" -o, --output FILE, --out FILE\n" +
+		"                           output file [env: OUTPUT]\n" +
+		"See the manual. Bugs: <bugs@example.com>\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+}
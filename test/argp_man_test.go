@@ -0,0 +1,88 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func Test_Man(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'v', Long: "verbose", Doc: "verbose output"},
+		{Long: "debug", Flags: argp.OPTION_HIDDEN, Doc: "debug output"},
+		{Doc: "Output options:"},
+		{Short: 'o', Long: "output", ArgName: "FILE", Doc: "output file\n\n.dot and \\backslash", Env: "PROG_OUTPUT"},
+		{Long: "out", Flags: argp.OPTION_ALIAS},
+		{Short: 'K', Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL, Doc: "kind"},
+		{Short: 'x', ArgName: "X", Flags: argp.OPTION_ARG_OPTIONAL},
+	}
+
+	buf := bytes.NewBufferString("")
+	program := newProgram(buf)
+	program.Authors = "Written by the prog authors."
+	program.Doc += "\nIt does things."
+	program.Arguments = []argp.Argument{{Name: "FILE", Doc: "input files", Flags: argp.ARG_VARIADIC}}
+	program.PrintMan(options, 1)
+
+	expect := "" +
+		".TH \"PROG\" \"1\" \"\" \"prog 1.0.0\" \"User Commands\"\n" +
+		".SH NAME\n" +
+		"prog \\- a test program\n" +
+		".SH SYNOPSIS\n" +
+		".B prog\n" +
+		"[\\-v?V]\n" +
+		"[\\-o FILE]\n" +
+		"[\\-K[KIND]]\n" +
+		"[\\-x[X]]\n" +
+		"[\\-\\-verbose]\n" +
+		"[\\-\\-output=FILE]\n" +
+		"[\\-\\-out=FILE]\n" +
+		"[\\-\\-kind[=KIND]]\n" +
+		"[\\-\\-help]\n" +
+		"[\\-\\-usage]\n" +
+		"[\\-\\-version]\n" +
+		"FILE...\n" +
+		".SH DESCRIPTION\n" +
+		"It does things.\n" +
+		".PP\n" +
+		"See the manual for details.\n" +
+		".SH ARGUMENTS\n" +
+		".TP\n" +
+		"\\fIFILE...\\fR\n" +
+		"input files\n" +
+		".SH OPTIONS\n" +
+		".TP\n" +
+		"\\fB\\-v\\fR, \\fB\\-\\-verbose\\fR\n" +
+		"verbose output\n" +
+		".SS \"Output options\"\n" +
+		".TP\n" +
+		"\\fB\\-o\\fR, \\fB\\-\\-output\\fR \\fIFILE\\fR, \\fB\\-\\-out\\fR \\fIFILE\\fR\n" +
+		"output file\n" +
+		".IP\n" +
+		"\\&.dot and \\ebackslash\n" +
+		".TP\n" +
+		"\\fB\\-K\\fR, \\fB\\-\\-kind\\fR[=\\fIKIND\\fR]\n" +
+		"kind\n" +
+		".TP\n" +
+		"\\fB\\-x\\fR[\\fIX\\fR]\n" +
+		".TP\n" +
+		"\\fB\\-?\\fR, \\fB\\-\\-help\\fR\n" +
+		"give this help list\n" +
+		".TP\n" +
+		"\\fB\\-\\-usage\\fR\n" +
+		"give a short usage message\n" +
+		".TP\n" +
+		"\\fB\\-V\\fR, \\fB\\-\\-version\\fR\n" +
+		"print program version\n" +
+		".SH ENVIRONMENT\n" +
+		".TP\n" +
+		".B PROG_OUTPUT\n" +
+		"Sets \\fB\\-\\-output\\fR.\n" +
+		".SH AUTHORS\n" +
+		"Written by the prog authors.\n" +
+		".SH BUGS\n" +
+		"Report bugs to <bugs@example.com>.\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+}
@@ -0,0 +1,106 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+var programOptions = []argp.Option{
+	{Short: 'o', Long: "output", ArgName: "<file>", Doc: "output file"},
+	{Short: 'v', Long: "verbose", Doc: "verbose output"},
+}
+
+func newProgram(buf *bytes.Buffer) *argp.Program {
+	return &argp.Program{
+		Name:       "prog",
+		Version:    "prog 1.0.0",
+		BugAddress: "<bugs@example.com>",
+		ArgsDoc:    "FILE...",
+		Doc:        "prog -- a test program",
+		PostDoc:    "See the manual for details.",
+		Output:     buf,
+	}
+}
+
+func Test_Program(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := newProgram(buf)
+
+	result, err := program.ParseArgs(programOptions, split("-v a.txt"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, buf.String(), "", "")
+
+	_, err = program.ParseArgs(programOptions, split("-V"))
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsEqual(t, buf.String(), "prog 1.0.0\n", "")
+
+	// the usage is wrapped at the terminal width
+	t.Setenv("COLUMNS", "80")
+	buf.Reset()
+	_, err = program.ParseArgs(programOptions, split("--usage"))
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsEqual(t, buf.String(), "Usage: prog [-v?V] [-o <file>] [--output=<file>] [--verbose] [--help] [--usage]\n"+
+		"            [--version] FILE...\n", "")
+
+	// help is handled even if an error follows
+	buf.Reset()
+	_, err = program.ParseArgs(programOptions, split("-? --unknown"))
+	harness.IsEqual(t, err, argp.ErrExit, "")
+
+	expect := "" +
+		"Usage: prog [options...] FILE...\n" +
+		"prog -- a test program\n" +
+		"\n" +
+		" -o, --output <file>       output file\n" +
+		" -v, --verbose             verbose output\n" +
+		"\n" +
+		" -?, --help                give this help list\n" +
+		"     --usage               give a short usage message\n" +
+		" -V, --version             print program version\n" +
+		"\n" +
+		"See the manual for details.\n" +
+		"\n" +
+		"Report bugs to <bugs@example.com>.\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_ProgramFlags(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := newProgram(buf)
+	program.Flags = argp.PARSE_REQUIRE_ORDER | argp.PARSE_LONG_PREFIX
+	program.Completer = argp.CompleteWords("-a.txt")
+
+	result, err := program.ParseArgs(programOptions, split("--verb a.txt -v"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, result.Options[0].Long, "verbose", "")
+	harness.IsEqual(t, len(result.Args), 2, "")
+
+	// the words after the first non-option argument are completed as arguments
+	_, err = program.ParseArgs(programOptions, []string{argp.CompleteCommand, "a.txt", "-"})
+	harness.IsEqual(t, err, argp.ErrExit, "")
+	harness.IsEqual(t, buf.String(), "-a.txt\n", "")
+}
+
+func Test_ProgramConflict(t *testing.T) {
+	buf := bytes.NewBufferString("")
+	program := &argp.Program{Name: "prog", Output: buf}
+	options := []argp.Option{
+		{Short: 'V', Long: "verify", Doc: "verify"},
+		{Short: '?', Long: "question", Doc: "question"},
+		{Long: "usage", Doc: "custom usage"},
+	}
+	table := program.Options(options)
+	harness.IsEqual(t, len(table), 5, "")
+	harness.IsEqual(t, table[4].Long, "help", "")
+	harness.IsEqual(t, table[4].Short, rune(0), "")
+
+	result, err := program.ParseArgs(options, split("-V --usage"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 2, "")
+	harness.IsEqual(t, buf.String(), "", "")
+}
@@ -0,0 +1,78 @@
+package argp_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func writeFile(t *testing.T, dir string, name string, data string) string {
+	path := filepath.Join(dir, name)
+	harness.IsNil(t, os.WriteFile(path, []byte(data), 0o644), "")
+	return path
+}
+
+func Test_ResponseFile(t *testing.T) {
+	dir := t.TempDir()
+	inner := writeFile(t, dir, "inner.rsp", "-b\n'arg 1' \"arg\\\"2\" arg\\ 3\n")
+	outer := writeFile(t, dir, "outer.rsp", "-a\n@"+inner+"\n-- @"+inner+"\n")
+
+	args, err := argp.ExpandResponseFiles([]string{"-c", "@" + outer, "@"})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(args, "|"), "-c|-a|-b|arg 1|arg\"2|arg 3|--|@"+inner+"|@", "")
+
+	args, err = argp.ExpandResponseFiles([]string{"--", "@" + inner})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, args[1], "@"+inner, "")
+
+	result, err := argp.ParseArgsFlags(options, []string{"@" + inner}, argp.PARSE_RESPONSE_FILE)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, len(result.Args), 3, "")
+}
+
+func Test_ResponseFileNegative(t *testing.T) {
+	dir := t.TempDir()
+	quote := writeFile(t, dir, "quote.rsp", "-a\n-b 'unterminated\n")
+	_, err := argp.ExpandResponseFiles([]string{"@" + quote})
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), quote+":2:4: unterminated quote", "")
+
+	loop := filepath.Join(dir, "loop.rsp")
+	writeFile(t, dir, "loop.rsp", "-a\n@"+loop+"\n")
+	_, err = argp.ExpandResponseFiles([]string{"@" + loop})
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), loop+":2: recursive response file: "+loop, "")
+
+	_, err = argp.ParseArgsFlags(options, []string{"@" + filepath.Join(dir, "missing")}, argp.PARSE_RESPONSE_FILE)
+	harness.IsTrue(t, os.IsNotExist(err), "")
+}
+
+func Test_ResponseFileNested(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "rf")
+	harness.IsNil(t, os.Mkdir(dir, 0o755), "")
+
+	// the nested path is relative to the file
+	writeFile(t, dir, "c.txt", "-c\n")
+	b := writeFile(t, dir, "b.txt", "-b @c.txt\n")
+	args, err := argp.ExpandResponseFiles([]string{"@" + b})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(args, "|"), "-b|-c", "")
+
+	// the error reading the nested file has the position
+	a := writeFile(t, dir, "a.txt", "-a\n@missing.txt\n")
+	_, err = argp.ExpandResponseFiles([]string{"@" + a})
+	harness.IsNotNil(t, err, "")
+	missing := filepath.Join(dir, "missing.txt")
+	harness.IsEqual(t, err.Error(), a+":2: cannot read response file: open "+missing+": no such file or directory", "")
+	harness.IsTrue(t, errors.Is(err, fs.ErrNotExist), "")
+	e := err.(argp.Error)
+	harness.IsEqual(t, e.Message, argp.ErrResponse, "")
+	harness.IsEqual(t, e.Line, 2, "")
+}
@@ -0,0 +1,105 @@
+package argp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+type testPairSplit struct {
+	line   string
+	expect []string
+}
+
+var splitPatterns = []testPairSplit{
+	{line: "", expect: []string{}},
+	{line: "  a  b\tc\n d ", expect: []string{"a", "b", "c", "d"}},
+	{line: `'a b' "c d"`, expect: []string{"a b", "c d"}},
+	{line: `a'b c'"d"e`, expect: []string{"ab cde"}},
+	{line: `'' ""`, expect: []string{"", ""}},
+	{line: `a\ b \'c\"`, expect: []string{"a b", `'c"`}},
+	{line: `'a\b' "a\b" "\$\"\\"`, expect: []string{`a\b`, `a\b`, `$"\`}},
+	{line: "a\\\nb \"c\\\nd\"", expect: []string{"ab", "cd"}},
+	{line: "a \\\n b", expect: []string{"a", "b"}},
+	{line: "\\\n", expect: []string{}},
+	{line: `"it's" 'say "hi"'`, expect: []string{"it's", `say "hi"`}},
+	{line: `a\`, expect: []string{`a\`}},
+	{line: "日本 '語 '", expect: []string{"日本", "語 "}},
+}
+
+func Test_SplitLine(t *testing.T) {
+	for _, ptn := range splitPatterns {
+		args, err := argp.SplitLine(ptn.line)
+		harness.IsNil(t, err, ptn.line)
+		harness.IsEqual(t, len(args), len(ptn.expect), ptn.line)
+		harness.IsEqual(t, strings.Join(args, "|"), strings.Join(ptn.expect, "|"), ptn.line)
+	}
+}
+
+func Test_SplitLineNegative(t *testing.T) {
+	_, err := argp.SplitLine(`-a "b c`)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "1:4: unterminated quote", "")
+
+	_, err = argp.SplitLine("-a\n-b 'c\nd")
+	harness.IsNotNil(t, err, "")
+	e, ok := err.(argp.Error)
+	harness.IsTrue(t, ok, "")
+	harness.IsEqual(t, e.Message, argp.ErrQuote, "")
+	harness.IsEqual(t, e.Line, 2, "")
+	harness.IsEqual(t, e.Column, 4, "")
+}
+
+func Test_ParseLine(t *testing.T) {
+	result, err := argp.ParseLine(options, `-o "out file.txt" --file='a b' "arg 0"`)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 2, "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "out file.txt", "")
+	harness.IsEqual(t, result.GetOpt("file").Optarg, "a b", "")
+	harness.IsEqual(t, result.Args[0], "arg 0", "")
+
+	_, err = argp.ParseLine(options, `-o "out`)
+	harness.IsNotNil(t, err, "")
+}
+
+var splitWindowsPatterns = []testPairSplit{
+	{line: "", expect: []string{}},
+	{line: `prog a b`, expect: []string{"prog", "a", "b"}},
+	{line: `"C:\Program Files\prog.exe" -a`, expect: []string{`C:\Program Files\prog.exe`, "-a"}},
+	{line: `C:\bin\prog.exe "a b" c`, expect: []string{`C:\bin\prog.exe`, "a b", "c"}},
+	{line: `prog a\\b a\\\\"b c" d`, expect: []string{"prog", `a\\b`, `a\\b c`, "d"}},
+	{line: `prog a\"b a\\\"b`, expect: []string{"prog", `a"b`, `a\"b`}},
+	{line: `prog "a""b" c`, expect: []string{"prog", `a"b c`}},
+	{line: `prog "" "a b`, expect: []string{"prog", "", "a b"}},
+	{line: "prog\ta\t\tb", expect: []string{"prog", "a", "b"}},
+}
+
+func Test_SplitWindows(t *testing.T) {
+	for _, ptn := range splitWindowsPatterns {
+		args := argp.SplitWindows(ptn.line)
+		harness.IsEqual(t, len(args), len(ptn.expect), ptn.line)
+		harness.IsEqual(t, strings.Join(args, "|"), strings.Join(ptn.expect, "|"), ptn.line)
+	}
+}
+
+func Test_QuoteWindows(t *testing.T) {
+	harness.IsEqual(t, argp.QuoteWindows(""), `""`, "")
+	harness.IsEqual(t, argp.QuoteWindows(`a\b`), `a\b`, "")
+	harness.IsEqual(t, argp.QuoteWindows("a b"), `"a b"`, "")
+	harness.IsEqual(t, argp.QuoteWindows(`a"b`), `"a\"b"`, "")
+	harness.IsEqual(t, argp.QuoteWindows(`a b\`), `"a b\\"`, "")
+	harness.IsEqual(t, argp.QuoteWindows(`a\"b`), `"a\\\"b"`, "")
+
+	args := []string{`C:\Program Files\prog.exe`, "", "a b", `a\"b\`, `c:\dir\ x\\`, "-o", `"`}
+	line := argp.JoinWindows(args)
+	actual := argp.SplitWindows(line)
+	harness.IsEqual(t, len(actual), len(args), line)
+	harness.IsEqual(t, strings.Join(actual, "|"), strings.Join(args, "|"), line)
+
+	result, err := argp.ParseArgs(options, argp.SplitWindows(`prog.exe -o "out file.txt" "arg 0"`)[1:])
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, result.GetOpt("output").Optarg, "out file.txt", "")
+	harness.IsEqual(t, result.Args[0], "arg 0", "")
+}
@@ -2,6 +2,7 @@ package argp_test
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"testing"
 
@@ -9,6 +10,15 @@ import (
 	"github.com/yamavol/go-argp/test/harness"
 )
 
+// The help messages depend on the terminal width and the ARGP_HELP_FMT
+// environment variable. Tests expect the default format, and set them by
+// t.Setenv if needed.
+func TestMain(m *testing.M) {
+	os.Unsetenv("COLUMNS")
+	os.Unsetenv("ARGP_HELP_FMT")
+	os.Exit(m.Run())
+}
+
 var options = []argp.Option{
 	{Doc: "CATEGORY 000:"},
 	{Short: 'a', Long: "aaa", Doc: "enable option a"},
@@ -38,8 +48,11 @@ var options = []argp.Option{
 }
 
 func split(str string) []string {
-	// TODO: split string with "" properly
-	return strings.Split(str, " ")
+	args, err := argp.SplitLine(str)
+	if err != nil {
+		panic(err)
+	}
+	return args
 }
 
 func Test_Parse(t *testing.T) {
@@ -334,3 +347,190 @@ func Test_OptionListPrinting2(t *testing.T) {
 		}
 	}
 }
+
+func Test_RequireOrder(t *testing.T) {
+	args := split("-a exec cmd -x --bbb -- -c")
+	result, err := argp.ParseArgsFlags(options, args, argp.PARSE_REQUIRE_ORDER)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, strings.Join(result.Args, " "), "exec cmd -x --bbb -- -c", "")
+
+	// single dash is a non-option argument
+	result, err = argp.ParseArgsFlags(options, split("-a - -b"), argp.PARSE_REQUIRE_ORDER)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, len(result.Args), 2, "")
+
+	// the terminator is consumed if it appears before the first non-option
+	result, err = argp.ParseArgsFlags(options, split("-a -- -b"), argp.PARSE_REQUIRE_ORDER)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, strings.Join(result.Args, " "), "-b", "")
+}
+
+func Test_PosixlyCorrect(t *testing.T) {
+	args := split("arg0 -a")
+	t.Setenv("POSIXLY_CORRECT", "1")
+	result, err := argp.ParseArgsFlags(options, args, argp.PARSE_POSIXLY_CORRECT)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 0, "")
+	harness.IsEqual(t, len(result.Args), 2, "")
+
+	// the environment variable is ignored without the flag
+	result, err = argp.ParseArgs(options, args)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 1, "")
+	harness.IsEqual(t, len(result.Args), 1, "")
+}
+
+func Test_Ordered(t *testing.T) {
+	args := split("-O2 a.c -xARG b.c -ab -- -c.c")
+	options := append(options, argp.Option{Short: 'O', ArgName: "<n>"})
+	result, err := argp.ParseArgs(options, args)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 4, "")
+	harness.IsEqual(t, len(result.Args), 3, "")
+	harness.IsEqual(t, len(result.Ordered), 7, "")
+
+	expect := []struct {
+		name  string
+		text  string
+		index int
+	}{
+		{"O", "2", 0},
+		{"", "a.c", 1},
+		{"x", "ARG", 2},
+		{"", "b.c", 3},
+		{"a", "", 4},
+		{"b", "", 4},
+		{"", "-c.c", 6},
+	}
+	for i, exp := range expect {
+		res := result.Ordered[i]
+		harness.IsEqual(t, res.IsArg(), exp.name == "", "")
+		harness.IsTrue(t, res.IsArg() || res.Is(exp.name), "")
+		harness.IsEqual(t, res.Optarg, exp.text, "")
+		harness.IsEqual(t, res.Index, exp.index, "")
+	}
+}
+
+func Test_LongPrefix(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'v', Long: "ver", Doc: "exact match"},
+		{Long: "verbose", Doc: "verbose output"},
+		{Long: "version", ArgName: "<ver>", Doc: "print version"},
+		{Long: "output", ArgName: "<file>", Doc: "output file"},
+		{Long: "outfile", Flags: argp.OPTION_ALIAS},
+	}
+
+	args := split("--ver --verb --vers=1 --out a.txt --outf b.txt")
+	result, err := argp.ParseArgsFlags(options, args, argp.PARSE_LONG_PREFIX)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 5, "")
+	harness.IsEqual(t, result.Options[0].Long, "ver", "")
+	harness.IsEqual(t, result.Options[1].Long, "verbose", "")
+	harness.IsEqual(t, result.Options[2].Long, "version", "")
+	harness.IsEqual(t, result.Options[2].Optarg, "1", "")
+	harness.IsEqual(t, result.Options[3].Long, "output", "")
+	harness.IsEqual(t, result.Options[3].InputString, "out", "")
+	harness.IsEqual(t, result.Options[4].Long, "output", "")
+
+	_, err = argp.ParseArgsFlags(options, split("--ve"), argp.PARSE_LONG_PREFIX)
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "ambiguous option: --ve (candidates: --ver, --verbose, --version)", "")
+
+	// prefix is not accepted without the flag
+	_, err = argp.ParseArgs(options, split("--verb"))
+	harness.IsNotNil(t, err, "")
+}
+
+func Test_Suggestion(t *testing.T) {
+	_, err := argp.ParseArgs(options, split("--ouput a.txt"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid option: --ouput (did you mean --output?)", "")
+
+	// aliases are suggested, hidden options are not
+	_, err = argp.ParseArgs(options, split("--fff"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid option: --fff (did you mean --ffff?)", "")
+
+	_, err = argp.ParseArgs(options, split("--secre"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid option: --secre", "")
+
+	_, err = argp.ParseArgs(options, split("--zzzzzz"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid option: --zzzzzz", "")
+
+	// no suggestions for the empty name
+	_, err = argp.ParseArgs([]argp.Option{{Long: "ab"}, {Long: "output"}}, split("--=x"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, len(err.(argp.Error).Candidates()), 0, "")
+}
+
+func Test_ErrorCompare(t *testing.T) {
+	_, err1 := argp.ParseArgs(options, split("--ouput"))
+	_, err2 := argp.ParseArgs(options, split("--ouput"))
+	harness.IsNotNil(t, err1, "")
+	harness.IsTrue(t, err1 == err2, "")
+	harness.IsEqual(t, strings.Join(err1.(argp.Error).Candidates(), "|"), "output", "")
+
+	_, err3 := argp.ParseArgs(options, split("-d"))
+	harness.IsFalse(t, err1 == err3, "")
+	harness.IsTrue(t, err3 == argp.Error{Option: options[4], Message: argp.ErrMissing}, "")
+
+	required := []argp.Option{{Short: 'a', Flags: argp.OPTION_REQUIRED}, {Short: 'b', Flags: argp.OPTION_REQUIRED}}
+	_, err1 = argp.ParseArgs(required, nil)
+	_, err2 = argp.ParseArgs(required, nil)
+	harness.IsTrue(t, err1 == err2, "")
+}
+
+func Test_Required(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'o', Long: "output", ArgName: "FILE", Flags: argp.OPTION_REQUIRED, Doc: "output file"},
+		{Short: 'O', Long: "out", Flags: argp.OPTION_ALIAS},
+		{Long: "level", ArgName: "N", Flags: argp.OPTION_REQUIRED, Env: "TEST_REQUIRED_LEVEL", Doc: "level"},
+		{Short: 'f', Flags: argp.OPTION_REQUIRED},
+		{Short: 'v', Long: "verbose", Doc: "verbose"},
+	}
+
+	result, err := argp.ParseArgs(options, split("-O a.txt --level 1 -f"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 3, "")
+
+	// all the missing options are reported
+	result, err = argp.ParseArgs(options, split("-v a.txt"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing required option: --output (-o), --level, -f", "")
+	e := err.(argp.Error)
+	harness.IsEqual(t, e.Message, argp.ErrRequired, "")
+	harness.IsEqual(t, e.Long, "output", "")
+	harness.IsEqual(t, strings.Join(e.Missing(), "|"), "--output (-o)|--level|-f", "")
+	harness.IsEqual(t, len(result.Args), 1, "arguments are consumed")
+
+	_, err = argp.ParseArgs(options, split("-o a.txt -f"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "missing required option: --level", "")
+
+	// the environment variable satisfies the option
+	t.Setenv("TEST_REQUIRED_LEVEL", "2")
+	_, err = argp.ParseArgs(options, split("-o a.txt -f"))
+	harness.IsNil(t, err, "")
+
+	// other errors are reported first
+	_, err = argp.ParseArgs(options, split("-x"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid option: -x", "")
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptListWidth(buf, options, 79)
+	expect := "" +
+		" -o, -O, --output FILE, --out FILE\n" +
+		"                           output file [required]\n" +
+		"     --level N             level [required] [env: TEST_REQUIRED_LEVEL]\n" +
+		" -f                        [required]\n" +
+		" -v, --verbose             verbose\n"
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	harness.IsEqual(t, argp.FormatSynopsis(options, "cmd", "ARGS", 200),
+		"Usage: cmd [-v] [--verbose] -o FILE --level=N -f ARGS\n", "")
+}
@@ -0,0 +1,37 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func Test_Synopsis(t *testing.T) {
+	options := []argp.Option{
+		{Doc: "OPTIONS:"},
+		{Short: 'a', Long: "", Doc: "doc"},
+		{Short: 'b', Long: "bb", Doc: "doc"},
+		{Short: 's', Long: "silent", Doc: "doc"},
+		{Short: 'q', Long: "", Flags: argp.OPTION_ALIAS},
+		{Short: 'o', Long: "output", ArgName: "FILE", Doc: "doc"},
+		{Short: 'K', Long: "kind", ArgName: "KIND", Flags: argp.OPTION_ARG_OPTIONAL, Doc: "doc"},
+		{Short: 'x', Long: "", ArgName: "X", Doc: "doc"},
+		{Short: 'H', Long: "hidden", Flags: argp.OPTION_HIDDEN, Doc: "doc"},
+		{Short: 'I', Long: "", Flags: argp.OPTION_ALIAS},
+	}
+
+	expect := "Usage: cmd [-absq] [-o FILE] [-K[KIND]] [-x X] [--bb] [--silent] [--output=FILE] [--kind[=KIND]] ARGS\n"
+	harness.IsEqual(t, argp.FormatSynopsis(options, "cmd", "ARGS", 200), expect, "")
+
+	expect = "" +
+		"Usage: cmd [-absq] [-o FILE] [-K[KIND]] [-x X]\n" +
+		"           [--bb] [--silent] [--output=FILE]\n" +
+		"           [--kind[=KIND]] ARGS\n"
+	buf := bytes.NewBufferString("")
+	argp.PrintSynopsis(buf, options, "cmd", "ARGS", 50)
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	harness.IsEqual(t, argp.FormatSynopsis(nil, "cmd", "", 0), "Usage: cmd\n", "")
+}
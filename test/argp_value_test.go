@@ -0,0 +1,138 @@
+package argp_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func Test_Value(t *testing.T) {
+	var (
+		name    string
+		verbose bool
+		jobs    int
+		size    int64
+		count   uint
+		limit   uint64
+		ratio   float64
+		timeout time.Duration
+		include []string
+		level   = 1
+	)
+	options := []argp.Option{
+		{Short: 'n', Long: "name", ArgName: "<name>", Value: argp.String(&name)},
+		{Short: 'v', Long: "verbose", Value: argp.Bool(&verbose)},
+		{Short: 'j', Long: "jobs", ArgName: "<n>", Value: argp.Int(&jobs)},
+		{Long: "size", ArgName: "<n>", Value: argp.Int64(&size)},
+		{Long: "count", ArgName: "<n>", Value: argp.Uint(&count)},
+		{Long: "limit", ArgName: "<n>", Value: argp.Uint64(&limit)},
+		{Long: "ratio", ArgName: "<r>", Value: argp.Float64(&ratio)},
+		{Long: "timeout", ArgName: "<d>", Value: argp.Duration(&timeout)},
+		{Short: 'I', ArgName: "<dir>", Value: argp.Strings(&include)},
+		{Short: 'O', ArgName: "<n>", Flags: argp.OPTION_ARG_OPTIONAL, Value: argp.Int(&level)},
+	}
+
+	args := split("-vn foo -j 4 --size=-5 --count 0x10 --limit 7 --ratio 2.5 --timeout 1m -Ia -Ib -O")
+	result, err := argp.ParseArgs(options, args)
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, len(result.Options), 11, "")
+	harness.IsEqual(t, name, "foo", "")
+	harness.IsTrue(t, verbose, "")
+	harness.IsEqual(t, jobs, 4, "")
+	harness.IsEqual(t, size, int64(-5), "")
+	harness.IsEqual(t, count, uint(16), "")
+	harness.IsEqual(t, limit, uint64(7), "")
+	harness.IsEqual(t, ratio, 2.5, "")
+	harness.IsEqual(t, timeout, time.Minute, "")
+	harness.IsEqual(t, strings.Join(include, ","), "a,b", "")
+	harness.IsEqual(t, level, 1, "optional argument omitted")
+	harness.IsEqual(t, options[8].Value.String(), "a,b", "")
+
+	_, err = argp.ParseArgs(options, split("-O3"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, level, 3, "")
+}
+
+func Test_ValueNegative(t *testing.T) {
+	var jobs int
+	var count uint
+	options := []argp.Option{
+		{Short: 'j', Long: "jobs", ArgName: "<n>", Value: argp.Int(&jobs)},
+		{Long: "count", ArgName: "<n>", Value: argp.Uint(&count)},
+	}
+
+	_, err := argp.ParseArgs(options, split("-j x4"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument 'x4': --jobs (-j)", "")
+
+	_, err = argp.ParseArgs(options, split("--count=-1"))
+	harness.IsNotNil(t, err, "")
+	e, ok := err.(argp.Error)
+	harness.IsTrue(t, ok, "")
+	harness.IsEqual(t, e.Message, argp.ErrValue, "")
+	harness.IsEqual(t, e.Long, "count", "")
+
+	// the cause is kept
+	_, err = argp.ParseArgs(options, split("-j 99999999999999999999"))
+	harness.IsNotNil(t, err, "")
+	harness.IsEqual(t, err.Error(), "invalid argument '99999999999999999999': --jobs (-j)", "")
+	harness.IsTrue(t, errors.Is(err, strconv.ErrRange), "")
+
+	port := portValue(0)
+	options = []argp.Option{{Short: 'p', ArgName: "<port>", Value: &port}}
+	_, err = argp.ParseArgs(options, split("-p 80"))
+	harness.IsNotNil(t, err, "")
+	harness.IsTrue(t, errors.Is(err, errPrivilegedPort), "")
+	harness.IsEqual(t, errors.Unwrap(err).Error(), "port 80 requires privileges", "")
+}
+
+var errPrivilegedPort = errors.New("port 80 requires privileges")
+
+// Value which rejects the privileged ports
+type portValue int
+
+func (v *portValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	} else if n < 1024 {
+		return errPrivilegedPort
+	}
+	*v = portValue(n)
+	return nil
+}
+
+func (v *portValue) String() string {
+	return strconv.Itoa(int(*v))
+}
+
+type levelValue struct {
+	level int
+}
+
+func (v *levelValue) Set(s string) error {
+	v.level = len(s)
+	return nil
+}
+
+func (v *levelValue) String() string {
+	return strings.Repeat("*", v.level)
+}
+
+func Test_BindValue(t *testing.T) {
+	var cfg struct {
+		Level levelValue `short:"l" argname:"<stars>" default:"*"`
+	}
+	_, err := argp.Bind(&cfg, []string{})
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, cfg.Level.level, 1, "")
+
+	_, err = argp.Bind(&cfg, split("-l ***"))
+	harness.IsNil(t, err, "")
+	harness.IsEqual(t, cfg.Level.level, 3, "")
+}
@@ -0,0 +1,69 @@
+package argp_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/yamavol/go-argp"
+	"github.com/yamavol/go-argp/test/harness"
+)
+
+func Test_OptListWrap(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'o', Long: "output", ArgName: "<file>", Doc: "write the output to the file instead of the standard output"},
+		{Long: "very-long-option-name", ArgName: "<value>", Doc: "option with a long name"},
+		{Short: 'n', Long: "name", ArgName: "<名前>", Doc: "名前を指定します"},
+		{Short: 'e', Long: "emoji", Doc: "🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉"},
+	}
+
+	expect := "" +
+		" -o, --output <file>       write the output to the file\n" +
+		"                           instead of the standard\n" +
+		"                           output\n" +
+		"     --very-long-option-name <value>\n" +
+		"                           option with a long name\n" +
+		" -n, --name <名前>         名前を指定します\n" +
+		" -e, --emoji               🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉🎉\n" +
+		"                           🎉🎉\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptListWidth(buf, options, 55)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_OptListColumns(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'o', Long: "output", ArgName: "<file>", Doc: "write the output to the file instead of the standard output"},
+	}
+
+	t.Setenv("COLUMNS", "60")
+	expect := "" +
+		" -o, --output <file>       write the output to the file\n" +
+		"                           instead of the standard output\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptList(buf, options)
+	harness.IsEqual(t, buf.String(), expect, "")
+
+	t.Setenv("COLUMNS", "")
+	expect = " -o, --output <file>       write the output to the file instead of the standard output\n"
+	buf.Reset()
+	argp.PrintOptListWidth(buf, options, 100)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
+
+func Test_OptListWrapIndent(t *testing.T) {
+	options := []argp.Option{
+		{Short: 'f', Long: "format", ArgName: "<fmt>", Doc: "output format:\n  json    JSON\n  text    plain text, which is the default format"},
+	}
+
+	expect := "" +
+		" -f, --format <fmt>        output format:\n" +
+		"                             json    JSON\n" +
+		"                             text    plain text, which\n" +
+		"                             is the default format\n"
+
+	buf := bytes.NewBufferString("")
+	argp.PrintOptListWidth(buf, options, 55)
+	harness.IsEqual(t, buf.String(), expect, "")
+}
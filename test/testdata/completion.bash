@@ -0,0 +1,25 @@
+# bash completion for my-prog
+
+_my_prog() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local prev="${COMP_WORDS[COMP_CWORD-1]}"
+    if [[ "$cur" == "=" ]]; then
+        cur=""
+        prev="$prev="
+    elif [[ "$prev" == "=" && $COMP_CWORD -ge 2 ]]; then
+        prev="${COMP_WORDS[COMP_CWORD-2]}="
+    fi
+    case "$prev" in
+        '-o'|'-q'|'--output'|'--output='|'--out'|'--out='|'--kind=')
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return
+            ;;
+    esac
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W '-v --verbose -o -q --output --out -K --kind -? --help' -- "$cur"))
+        return
+    fi
+    COMPREPLY=($(compgen -f -- "$cur"))
+}
+
+complete -F _my_prog 'my-prog'
@@ -0,0 +1,5 @@
+# fish completion for my-prog
+complete -c 'my-prog' -s 'v' -l 'verbose' -d 'verbose output'
+complete -c 'my-prog' -s 'o' -s 'q' -l 'output' -l 'out' -r -d 'output [file]: it\'s'
+complete -c 'my-prog' -s 'K' -l 'kind' -d 'kind'
+complete -c 'my-prog' -s '?' -l 'help'